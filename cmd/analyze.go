@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"syscall"
 
-	"git-activity/internal"
+	"git-activity/pkg/activity"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -13,56 +21,209 @@ import (
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze [repos...]",
 	Short: "Analyze multiple Git repositories and combine their data",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Analyze multiple Git repositories and combine their data.
+
+Repositories are given as arguments or listed under 'repos' in the config
+file. All flags can also be set in the config file, using their long names as
+keys, or with GIT_ACTIVITY_* environment variables, e.g. GIT_ACTIVITY_MODE.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Retrieve flag values
-		startStr := viper.GetString("start")
-		endStr := viper.GetString("end")
-		format := viper.GetString("format")
-		grouped := viper.GetBool("grouped")
-		mode := viper.GetString("mode")
-		peopleFile := viper.GetString("people")
-		bars := viper.GetString("bars")
+		// Repositories on the command line replace those of the config file
+		if len(args) == 0 {
+			args = configPaths(viper.GetViper(), "repos")
+		}
+		if len(args) == 0 {
+			log.Fatalf("No repositories given; pass them as arguments or list them under 'repos' in %s", defaultConfigFile)
+		}
 
-		// Parse dates
-		start, end := parseDateRange(startStr, endStr)
+		// Retrieve flag values
+		start, startDate := parseDateBound("start", configDate(viper.GetViper(), "start"), false)
+		end, endDate := parseDateBound("end", configDate(viper.GetViper(), "end"), true)
+		peopleFile := configPath(viper.GetViper(), "people")
+		mailmapFile := configPath(viper.GetViper(), "mailmap")
+		outputDir := configPath(viper.GetViper(), "output-dir")
+		dataFormats := viper.GetStringSlice("output-data")
+		keepGoing := viper.GetBool("keep-going")
+		timeout := viper.GetDuration("timeout")
+		partial := viper.GetBool("partial")
+		cacheDir := resolveCacheDir()
+		if viper.GetBool("no-cache") {
+			cacheDir = ""
+		}
 
-		// Validate format
-		if format != "png" && format != "svg" {
-			log.Fatalf("Invalid format '%s'. Supported formats are 'png' and 'svg'.", format)
+		// "none" counts all files
+		excludeClasses := viper.GetStringSlice("exclude-classes")
+		if slices.Contains(excludeClasses, "none") {
+			excludeClasses = []string{}
 		}
 
-		// Validate mode
-		if mode != "commits" && mode != "lines" {
-			log.Fatalf("Invalid mode '%s'. Supported modes are 'commits' or 'lines'.", mode)
+		opts := activity.Options{
+			Repos:          args,
+			Refs:           viper.GetStringSlice("refs"),
+			AllBranches:    viper.GetBool("all-branches"),
+			FirstParent:    viper.GetBool("first-parent"),
+			Merges:         viper.GetString("merges"),
+			Start:          start,
+			End:            end,
+			StartDate:      startDate,
+			EndDate:        endDate,
+			SinceTag:       viper.GetString("since-tag"),
+			UntilTag:       viper.GetString("until-tag"),
+			DateSource:     viper.GetString("date-source"),
+			Timezone:       viper.GetString("timezone"),
+			Mode:           viper.GetString("mode"),
+			Unknown:        viper.GetString("unknown"),
+			Include:        viper.GetStringSlice("include"),
+			Exclude:        viper.GetStringSlice("exclude"),
+			ExcludeClasses: excludeClasses,
+			Jobs:           viper.GetInt("jobs"),
+			CacheDir:       cacheDir,
+			KeepGoing:      keepGoing,
 		}
 
-		if bars != "repo" && bars != "dev" && bars != "repository" && bars != "developer" && bars != "" {
-			log.Fatalf("Invalid bars mode '%s'. Supported modes are 'repository', 'developer', or 'flat'.", bars)
+		chartOpts := activity.ChartOptions{
+			Format:   viper.GetString("format"),
+			Stacking: viper.GetString("bars"),
+			Timeline: viper.GetString("timeline"),
+			Title:    viper.GetString("title"),
+			Filters:  reportFilters(args),
 		}
 
 		// Parse developer aliases
-		var aliases internal.DeveloperAliases
 		if peopleFile != "" {
 			var err error
-			aliases, err = parseDeveloperAliases(peopleFile)
+			opts.Aliases, opts.Zones, err = activity.LoadPeople(peopleFile)
 			if err != nil {
 				log.Fatalf("Error parsing people file: %v", err)
 			}
 		}
 
-		// Perform analysis
-		outputPrefix, combinedActivity := internal.AnalyzeRepositories(args, start, end, mode, aliases)
+		// Add aliases and teams declared in the config file
+		if err := addConfigPeople(&opts); err != nil {
+			log.Fatalf("Error parsing config file: %v", err)
+		}
+
+		// Parse additional mailmap entries
+		if mailmapFile != "" {
+			var err error
+			opts.Mailmap, err = activity.LoadMailmap(mailmapFile)
+			if err != nil {
+				log.Fatalf("Error parsing mailmap file: %v", err)
+			}
+		}
+
+		// Validate everything before the analysis starts
+		for _, err := range []error{opts.Validate(), chartOpts.Validate(), activity.ValidateDataFormats(dataFormats)} {
+			if err != nil {
+				log.Fatalf("Invalid options: %v", err)
+			}
+		}
 
-		err := internal.GenerateCharts(combinedActivity, grouped, mode, bars, outputPrefix, format, aliases)
+		// The analysis stops on Ctrl-C or SIGTERM and after the timeout. A
+		// second signal kills the process right away.
+		signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-signalCtx.Done()
+			stop()
+		}()
+		ctx := signalCtx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		// Report progress on stderr, so that it does not mix with charts
+		// drawn on stdout
+		stopProgress := func() {}
+		if !viper.GetBool("quiet") {
+			slog.Info("Analyzing repositories", "count", len(opts.Repos), "mode", opts.Mode)
+			opts.Progress = activity.NewProgressTracker()
+			stopProgress = activity.ShowProgress(opts.Progress, os.Stderr)
+		}
+
+		stopProfiling, err := startProfiling()
 		if err != nil {
-			log.Fatalf("Error generating charts: %v", err)
+			log.Fatalf("Error: %v", err)
+		}
+
+		// Perform analysis; without --keep-going, any failed repository ends
+		// the run
+		result, err := activity.Analyze(ctx, opts)
+		stopProgress()
+		if err := stopProfiling(); err != nil {
+			log.Printf("Error writing profile: %v", err)
+		}
+		var analysisErr *activity.AnalysisError
+		if errors.As(err, &analysisErr) {
+			printFailureSummary(analysisErr)
+		}
+
+		interrupted := result != nil && result.Partial()
+		switch {
+		case interrupted:
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", interruptionCause(ctx))
+			if !partial {
+				fmt.Fprintln(os.Stderr, "No charts were generated; use --partial to generate them from the data collected so far.")
+				os.Exit(exitInterrupted)
+			}
+		case analysisErr != nil:
+			if result == nil || len(result.Activity.Repos) == 0 {
+				os.Exit(exitRepoFailed)
+			}
+		case err != nil:
+			log.Fatalf("Error analyzing repositories: %v", err)
+		}
+
+		if outputDir != "" {
+			if err := os.MkdirAll(outputDir, 0o755); err != nil {
+				log.Fatalf("Error creating output directory: %v", err)
+			}
+			chartOpts.OutputPrefix = filepath.Join(outputDir, result.Name)
+		}
+
+		if err := activity.RenderCharts(result, chartOpts); err != nil {
+			log.Fatalf("Error rendering charts: %v", err)
+		}
+
+		if err := activity.ExportData(result, chartOpts.OutputPrefix, dataFormats); err != nil {
+			log.Fatalf("Error exporting data: %v", err)
+		}
+
+		printUnmappedSummary(result.UnmappedIdentities(), result.Options.Unknown)
+		fmt.Printf("Date window: %s\n", result.Options.Window())
+
+		if interrupted {
+			fmt.Println("Repository analysis interrupted, charts show partial data.")
+			os.Exit(exitInterrupted)
+		}
+
+		if analysisErr != nil {
+			fmt.Printf("Repository analysis complete, %d of %d repositories failed.\n", len(analysisErr.Failed), len(args))
+			os.Exit(exitRepoFailed)
 		}
 
 		fmt.Println("Repository analysis complete.")
 	},
 }
 
+const (
+	// exitRepoFailed is the exit status if any repository could not be
+	// analyzed.
+	exitRepoFailed = 2
+	// exitInterrupted is the exit status if the analysis was interrupted or
+	// timed out.
+	exitInterrupted = 3
+)
+
+// interruptionCause describes why ctx was canceled.
+func interruptionCause(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "analysis timed out"
+	}
+	return "analysis interrupted"
+}
+
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
 }
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"git-activity/pkg/activity"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the per-commit cache",
+}
+
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the cached repositories and the size of the cache",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cacheDir := resolveCacheDir()
+
+		summaries, err := activity.ListCaches(cacheDir)
+		if err != nil {
+			log.Fatalf("Error reading cache: %v", err)
+		}
+
+		fmt.Printf("Cache directory: %s\n", cacheDir)
+		var totalSize int64
+		totalCommits := 0
+		for _, summary := range summaries {
+			repoPath := summary.RepoPath
+			if repoPath == "" {
+				repoPath = "(unreadable)"
+			}
+			fmt.Printf("  %-60s %8d commits %10s  last used %s\n",
+				repoPath, summary.Commits, formatBytes(summary.Size), summary.LastUsed.Format("2006-01-02 15:04"))
+			totalSize += summary.Size
+			totalCommits += summary.Commits
+		}
+		fmt.Printf("%d repositories, %d commits, %s\n", len(summaries), totalCommits, formatBytes(totalSize))
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove caches of missing repositories and caches not used recently",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		olderThan, err := cmd.Flags().GetDuration("older-than")
+		if err != nil {
+			log.Fatalf("Error reading older-than flag: %v", err)
+		}
+
+		removed, err := activity.PruneCaches(resolveCacheDir(), olderThan)
+		if err != nil {
+			log.Fatalf("Error pruning cache: %v", err)
+		}
+
+		for _, summary := range removed {
+			fmt.Printf("Removed cache for %s\n", summary.RepoPath)
+		}
+		fmt.Printf("Pruned %d caches.\n", len(removed))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached data",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := activity.ClearCaches(resolveCacheDir())
+		if err != nil {
+			log.Fatalf("Error clearing cache: %v", err)
+		}
+
+		fmt.Printf("Removed %d caches.\n", len(removed))
+	},
+}
+
+// resolveCacheDir returns the configured cache directory or the default one.
+func resolveCacheDir() string {
+	if cacheDir := configPath(viper.GetViper(), "cache-dir"); cacheDir != "" {
+		return cacheDir
+	}
+	return activity.DefaultCacheDir()
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	cachePruneCmd.Flags().Duration("older-than", 0, "Also remove caches not used for this long (e.g. 720h)")
+
+	cacheCmd.AddCommand(cacheInfoCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"git-activity/pkg/activity"
+
+	"github.com/spf13/viper"
+)
+
+// defaultConfigFile is read from the working directory unless --config is
+// given.
+const defaultConfigFile = ".git-activity.yaml"
+
+// envPrefix prefixes the environment variables overriding settings, e.g.
+// GIT_ACTIVITY_MODE=lines for --mode.
+const envPrefix = "GIT_ACTIVITY"
+
+// initConfig reads the config file, whose keys are the long flag names, and
+// applies the profile selected with --profile. Flags take precedence over
+// environment variables, which take precedence over the config file.
+func initConfig() {
+	setupEnv(viper.GetViper())
+
+	profile := viper.GetString("profile")
+	configFile := viper.GetString("config")
+	if configFile == "" {
+		if _, err := os.Stat(defaultConfigFile); err != nil {
+			if profile != "" {
+				log.Fatalf("Error: profile '%s' selected, but there is no config file", profile)
+			}
+			return
+		}
+		configFile = defaultConfigFile
+	}
+
+	viper.SetConfigFile(configFile)
+	if err := viper.ReadInConfig(); err != nil {
+		log.Fatalf("Error reading config file: %v", err)
+	}
+	if profile != "" {
+		if err := applyProfile(viper.GetViper(), profile); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	slog.Info("Using config file", "file", viper.ConfigFileUsed(), "profile", profile)
+}
+
+// setupEnv lets GIT_ACTIVITY_* environment variables override settings.
+func setupEnv(v *viper.Viper) {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+}
+
+// envName returns the environment variable overriding key.
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// profileNames returns the names of the profiles defined under 'profiles' in
+// the config file of v, in lowercase like all keys.
+func profileNames(v *viper.Viper) []string {
+	names := make([]string, 0)
+	for name := range v.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileSettings returns the settings of a profile of the config file of v.
+// A profile without settings is defined but empty.
+func profileSettings(v *viper.Viper, name string) (map[string]any, error) {
+	names := profileNames(v)
+	if !slices.Contains(names, strings.ToLower(name)) {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("unknown profile '%s'; the config file defines no profiles", name)
+		}
+		return nil, fmt.Errorf("unknown profile '%s'; defined profiles are %s", name, strings.Join(names, ", "))
+	}
+
+	profile := v.Sub("profiles." + strings.ToLower(name))
+	if profile == nil {
+		return map[string]any{}, nil
+	}
+	return profile.AllSettings(), nil
+}
+
+// applyProfile merges the settings of a profile over the top-level settings
+// of the config file of v. Environment variables and flags still override
+// them.
+func applyProfile(v *viper.Viper, name string) error {
+	settings, err := profileSettings(v, name)
+	if err != nil {
+		return err
+	}
+	return v.MergeConfigMap(settings)
+}
+
+// configDate returns the date set for key in v. YAML parses unquoted dates
+// such as 2024-01-01 as timestamps, which are formatted back as they were
+// written.
+func configDate(v *viper.Viper, key string) string {
+	if t, ok := v.Get(key).(time.Time); ok {
+		return formatConfigTime(t)
+	}
+	return v.GetString(key)
+}
+
+func formatConfigTime(t time.Time) string {
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
+
+// pathKeys are the settings holding paths, see configPath.
+var pathKeys = []string{"repos", "people", "mailmap", "output-dir", "cache-dir"}
+
+// configPath returns the path set for key in v. Relative paths from the
+// config file are resolved against its directory, so that a checked-in config
+// works from any working directory.
+func configPath(v *viper.Viper, key string) string {
+	path := v.GetString(key)
+	if path == "" || !fromConfigFile(v, key) {
+		return path
+	}
+	return resolveConfigPath(v, path)
+}
+
+// configPaths is configPath for a list of paths.
+func configPaths(v *viper.Viper, key string) []string {
+	paths := v.GetStringSlice(key)
+	if !fromConfigFile(v, key) {
+		return paths
+	}
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		resolved[i] = resolveConfigPath(v, path)
+	}
+	return resolved
+}
+
+func resolveConfigPath(v *viper.Viper, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(v.ConfigFileUsed()), path)
+}
+
+// fromConfigFile reports whether the value of key in v comes from the config
+// file rather than from a flag or an environment variable.
+func fromConfigFile(v *viper.Viper, key string) bool {
+	if !v.InConfig(key) {
+		return false
+	}
+	if flag := rootCmd.PersistentFlags().Lookup(key); flag != nil && flag.Changed {
+		return false
+	}
+	_, ok := os.LookupEnv(envName(key))
+	return !ok
+}
+
+// addConfigPeople adds the developers listed under 'aliases' and the teams
+// listed under 'teams' in the config file to the aliases of opts. Both use
+// the line format of the people file, e.g. "Alice|alice@example.com" and
+// "Backend|Alice|bob@example.com".
+func addConfigPeople(opts *activity.Options) error {
+	people := viper.GetStringSlice("aliases")
+	teams := viper.GetStringSlice("teams")
+	if len(people) == 0 && len(teams) == 0 {
+		return nil
+	}
+
+	if opts.Aliases == nil {
+		opts.Aliases, opts.Zones = activity.DeveloperAliases{}, activity.DeveloperZones{}
+	}
+	if err := activity.AddPeople(opts.Aliases, opts.Zones, people); err != nil {
+		return err
+	}
+	return activity.AddTeams(opts.Aliases, opts.Zones, teams)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	httppprof "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	"github.com/felixge/fgprof"
+	"github.com/spf13/viper"
+)
+
+// startDebugServer serves the net/http/pprof and fgprof handlers on addr,
+// e.g. "localhost:6060". The handlers are registered on their own mux, so they
+// are not reachable through any other server.
+func startDebugServer(addr string) error {
+	// Listen right away so that an address in use is reported as an error
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("could not start debug server: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", httppprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+	mux.Handle("/debug/fgprof", fgprof.Handler())
+
+	slog.Info("Serving profiling data", "url", fmt.Sprintf("http://%s/debug/pprof/", listener.Addr()))
+	go func() {
+		log.Println(http.Serve(listener, mux))
+	}()
+	return nil
+}
+
+// startProfiling starts the CPU profile and execution trace requested with
+// --cpuprofile and --trace. The returned function stops them and writes the
+// heap profile requested with --memprofile.
+func startProfiling() (stop func() error, err error) {
+	var stops []func() error
+	stopAll := func() error {
+		var errs []error
+		for _, stop := range stops {
+			errs = append(errs, stop())
+		}
+		return errors.Join(errs...)
+	}
+
+	if filename := viper.GetString("cpuprofile"); filename != "" {
+		f, err := os.Create(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("could not start CPU profile: %w", err)
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+
+	if filename := viper.GetString("trace"); filename != "" {
+		f, err := os.Create(filename)
+		if err != nil {
+			stopAll()
+			return nil, fmt.Errorf("could not create trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stopAll()
+			return nil, fmt.Errorf("could not start trace: %w", err)
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+
+	if filename := viper.GetString("memprofile"); filename != "" {
+		stops = append(stops, func() error {
+			f, err := os.Create(filename)
+			if err != nil {
+				return fmt.Errorf("could not create memory profile: %w", err)
+			}
+			defer f.Close()
+
+			// Only count objects that are still in use
+			runtime.GC()
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				return fmt.Errorf("could not write memory profile: %w", err)
+			}
+			return nil
+		})
+	}
+
+	return stopAll, nil
+}
@@ -1,66 +1,119 @@
 package cmd
 
 import (
-	"bufio"
-	"git-activity/internal"
+	"fmt"
+	"git-activity/pkg/activity"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"time"
-)
 
-func parseDateRange(startStr, endStr string) (time.Time, time.Time) {
-	var start, end time.Time
-	var err error
+	"github.com/spf13/viper"
+)
 
-	if startStr != "" {
-		start, err = time.Parse("2006-01-02", startStr)
-		if err != nil {
-			log.Fatalf("Invalid start date format: %v", err)
-		}
+// parseDateBound parses --start or --end as either an exact RFC 3339
+// timestamp or a day in YYYY-MM-DD format, see activity.ParsePeriod.
+func parseDateBound(name, expr string, end bool) (time.Time, string) {
+	t, day, err := resolveDateBound(expr, end)
+	if err != nil {
+		log.Fatalf("Invalid %s date: %v", name, err)
 	}
+	return t, day
+}
 
-	if endStr != "" {
-		end, err = time.Parse("2006-01-02", endStr)
-		if err != nil {
-			log.Fatalf("Invalid end date format: %v", err)
-		}
+// resolveDateBound is parseDateBound returning the error.
+func resolveDateBound(expr string, end bool) (time.Time, string, error) {
+	if expr == "" {
+		return time.Time{}, "", nil
 	}
 
-	if !start.IsZero() && !end.IsZero() && start.After(end) {
-		log.Fatalf("Start date cannot be after end date.")
+	// Timestamps bound the window exactly
+	if t, err := time.Parse(time.RFC3339, expr); err == nil {
+		return t, "", nil
 	}
 
-	return start, end
+	// A start date is the first day of its period, an end date the last one
+	first, last, err := activity.ParsePeriod(expr, time.Now())
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	if end {
+		return time.Time{}, last.Format(time.DateOnly), nil
+	}
+	return time.Time{}, first.Format(time.DateOnly), nil
 }
 
-// ParseDeveloperAliases parses a file into a map of aliases to developer names
-func parseDeveloperAliases(filename string) (internal.DeveloperAliases, error) {
-	aliases := internal.DeveloperAliases{}
-
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// printUnmappedSummary warns about authors that are missing from the people
+// file, most active first, so they can be added to it or to .mailmap.
+func printUnmappedSummary(unmapped map[string]int, policy string) {
+	if len(unmapped) == 0 {
+		return
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, "|")
-		if len(parts) < 2 {
-			continue // Skip invalid lines
+	identities := make([]string, 0, len(unmapped))
+	for identity := range unmapped {
+		identities = append(identities, identity)
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		if unmapped[identities[i]] != unmapped[identities[j]] {
+			return unmapped[identities[i]] > unmapped[identities[j]]
 		}
+		return identities[i] < identities[j]
+	})
 
-		name := parts[0]
-		for _, alias := range parts[1:] {
-			aliases[strings.ToLower(strings.TrimSpace(alias))] = name
+	fmt.Fprintf(os.Stderr, "Warning: %d identities are not in the people file (policy '%s'):\n", len(identities), policy)
+	for _, identity := range identities {
+		fmt.Fprintf(os.Stderr, "  %6d  %s\n", unmapped[identity], identity)
+	}
+}
+
+// printFailureSummary lists the repositories that could not be analyzed.
+func printFailureSummary(analysisErr *activity.AnalysisError) {
+	fmt.Fprintf(os.Stderr, "Error: %d repositories could not be analyzed:\n", len(analysisErr.Failed))
+	for _, failure := range analysisErr.Failed {
+		fmt.Fprintf(os.Stderr, "  %s: %v\n", failure.RepoPath, failure.Err)
+	}
+}
+
+// reportFilters lists the settings that shaped the analysis for the summary
+// of the HTML report.
+func reportFilters(repos []string) []activity.ReportFilter {
+	orDefault := func(values []string, fallback string) string {
+		if len(values) == 0 {
+			return fallback
 		}
+		return strings.Join(values, ", ")
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	refs := orDefault(viper.GetStringSlice("refs"), "HEAD")
+	if viper.GetBool("all-branches") {
+		refs = "all branches"
 	}
 
-	return aliases, nil
+	filters := []activity.ReportFilter{
+		{Name: "Repositories", Value: strings.Join(repos, ", ")},
+		{Name: "Refs", Value: refs},
+		{Name: "Included paths", Value: orDefault(viper.GetStringSlice("include"), "all")},
+		{Name: "Excluded paths", Value: orDefault(viper.GetStringSlice("exclude"), "none")},
+		{Name: "Excluded files", Value: orDefault(viper.GetStringSlice("exclude-classes"), "none")},
+		{Name: "Merge commits", Value: viper.GetString("merges")},
+		{Name: "First parent only", Value: fmt.Sprint(viper.GetBool("first-parent"))},
+		{Name: "Date source", Value: viper.GetString("date-source")},
+		{Name: "Time zone", Value: viper.GetString("timezone")},
+		{Name: "Unknown authors", Value: viper.GetString("unknown")},
+	}
+	if tag := viper.GetString("since-tag"); tag != "" {
+		filters = append(filters, activity.ReportFilter{Name: "Since tag", Value: tag})
+	}
+	if tag := viper.GetString("until-tag"); tag != "" {
+		filters = append(filters, activity.ReportFilter{Name: "Until tag", Value: tag})
+	}
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		filters = append(filters, activity.ReportFilter{Name: "Config file", Value: configFile})
+	}
+	if profile := viper.GetString("profile"); profile != "" {
+		filters = append(filters, activity.ReportFilter{Name: "Profile", Value: profile})
+	}
+	return filters
 }
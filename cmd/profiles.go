@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Inspect the analysis profiles of the config file",
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the profiles of the config file with their resolved settings",
+	Long: `List the profiles of the config file with their resolved settings.
+
+Each setting is shown with its source: a flag, an environment variable, the
+profile, the top level of the config file, or the default.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		configFile := viper.ConfigFileUsed()
+		if configFile == "" {
+			log.Fatalf("No config file; create %s or pass --config", defaultConfigFile)
+		}
+
+		names := profileNames(viper.GetViper())
+		fmt.Printf("Config file: %s\n", configFile)
+		if len(names) == 0 {
+			fmt.Println("No profiles defined.")
+			return
+		}
+
+		for _, name := range names {
+			settings, err := resolveProfile(configFile, name)
+			if err != nil {
+				log.Fatalf("Error resolving profile '%s': %v", name, err)
+			}
+
+			fmt.Printf("\n%s\n", name)
+			width := 0
+			for _, setting := range settings {
+				width = max(width, len(setting.key))
+			}
+			for _, setting := range settings {
+				fmt.Printf("  %-*s  %-40s  (%s)\n", width, setting.key, setting.value, setting.source)
+			}
+		}
+	},
+}
+
+// profileSetting is one resolved setting of a profile.
+type profileSetting struct {
+	key    string
+	value  string
+	source string // "flag", "env", "profile", "config" or "default"
+}
+
+// resolveProfile loads the config file with the named profile applied into
+// a fresh Viper instance, with the same flags and environment variables as
+// the running command, and returns all settings sorted by key.
+func resolveProfile(configFile, name string) ([]profileSetting, error) {
+	v := viper.New()
+	setupEnv(v)
+	if err := v.BindPFlags(rootCmd.PersistentFlags()); err != nil {
+		return nil, err
+	}
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	profile, err := profileSettings(v, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.MergeConfigMap(profile); err != nil {
+		return nil, err
+	}
+
+	var settings []profileSetting
+	for _, key := range v.AllKeys() {
+		if key == "config" || key == "profile" || strings.HasPrefix(key, "profiles.") {
+			continue
+		}
+
+		source := "default"
+		_, inProfile := profile[key]
+		_, inEnv := os.LookupEnv(envName(key))
+		switch flag := rootCmd.PersistentFlags().Lookup(key); {
+		case flag != nil && flag.Changed:
+			source = "flag"
+		case inEnv:
+			source = "env"
+		case inProfile:
+			source = "profile"
+		case v.InConfig(key):
+			source = "config"
+		}
+
+		value, err := resolveSetting(v, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		settings = append(settings, profileSetting{key: key, value: value, source: source})
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].key < settings[j].key
+	})
+	return settings, nil
+}
+
+// resolveSetting formats the value of key in v as the analysis uses it:
+// relative paths from the config file are resolved against its directory, and
+// dates to the day or instant they denote.
+func resolveSetting(v *viper.Viper, key string) (string, error) {
+	switch {
+	case key == "repos":
+		return formatSetting(configPaths(v, key)), nil
+	case slices.Contains(pathKeys, key):
+		return configPath(v, key), nil
+	case key == "start" || key == "end":
+		t, day, err := resolveDateBound(configDate(v, key), key == "end")
+		if err != nil || day != "" || t.IsZero() {
+			return day, err
+		}
+		return t.Format(time.RFC3339), nil
+	}
+
+	value := v.Get(key)
+	if values, ok := value.([]any); ok {
+		strs := make([]string, len(values))
+		for i, value := range values {
+			strs[i] = fmt.Sprint(value)
+		}
+		value = strs
+	}
+	return formatSetting(value), nil
+}
+
+// formatSetting formats a setting value for display, lists comma-separated.
+func formatSetting(value any) string {
+	switch value := value.(type) {
+	case []string:
+		return strings.Join(value, ", ")
+	case time.Time:
+		return formatConfigTime(value)
+	}
+	return fmt.Sprint(value)
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
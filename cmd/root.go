@@ -5,10 +5,8 @@ import (
 	"log"
 	"os"
 
-	"net/http"
-	_ "net/http/pprof"
+	"git-activity/pkg/activity"
 
-	"github.com/felixge/fgprof"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -22,14 +20,16 @@ var rootCmd = &cobra.Command{
 		// Default behavior when no subcommands are provided
 		fmt.Println("Use 'git-activity analyze --help' to get started.")
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if addr := viper.GetString("debug-addr"); addr != "" {
+			if err := startDebugServer(addr); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+	},
 }
 
 func Execute() {
-	http.DefaultServeMux.Handle("/debug/fgprof", fgprof.Handler())
-	go func() {
-		log.Println(http.ListenAndServe(":6060", nil))
-	}()
-
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -43,21 +43,81 @@ func MustBind(flagName string, flag *pflag.Flag) {
 }
 
 func init() {
+	cobra.OnInitialize(initConfig)
+
 	// Add persistent flags to root command
-	rootCmd.PersistentFlags().StringP("start", "s", "", "Start date for analysis (YYYY-MM-DD)")
-	rootCmd.PersistentFlags().StringP("end", "e", "", "End date for analysis (YYYY-MM-DD)")
-	rootCmd.PersistentFlags().StringP("format", "f", "png", "Output format (png or svg)")
-	rootCmd.PersistentFlags().BoolP("grouped", "g", false, "Generate grouped bar charts")
-	rootCmd.PersistentFlags().StringP("mode", "m", "commits", "Mode of analysis: 'commits' or 'lines'")
+	rootCmd.PersistentFlags().String("config", "", "Config file (default: .git-activity.yaml in the working directory, if it exists)")
+	rootCmd.PersistentFlags().String("profile", "", "Profile of the config file to apply, see 'git-activity profiles list'")
+	rootCmd.PersistentFlags().StringP("start", "s", "", "Start date for analysis: YYYY-MM-DD, a relative date such as '30d' or '6 months ago', a period such as 'last-quarter', '2024-Q3' or '2024-W12', or an RFC 3339 timestamp")
+	rootCmd.PersistentFlags().StringP("end", "e", "", "End date for analysis, inclusive, in the same formats as --start; a period ends on its last day")
+	rootCmd.PersistentFlags().String("since-tag", "", "Only count commits after the commit of this tag, resolved in each repository")
+	rootCmd.PersistentFlags().String("until-tag", "", "Only count commits up to the commit of this tag, resolved in each repository")
+	rootCmd.PersistentFlags().StringP("format", "f", "png", "Output format: 'png', 'svg', 'html' (a single report with all charts) or 'term' (charts on stdout)")
+	rootCmd.PersistentFlags().StringP("mode", "m", "commits", "Mode of analysis: 'commits', 'lines', 'added', 'deleted' or 'net'")
 	rootCmd.PersistentFlags().StringP("bars", "b", "", "Stacking mode for bar charts: 'repository', 'developer', or leave empty for flat")
 	rootCmd.PersistentFlags().StringP("people", "p", "", "File containing developer aliases")
+	rootCmd.PersistentFlags().String("unknown", "author-name", "Fallback for authors missing from the people file: 'author-name', 'email', 'unknown' or 'drop'")
+	rootCmd.PersistentFlags().String("date-source", "author", "Commit date used for the date range and all buckets: 'author' or 'committer'")
+	rootCmd.PersistentFlags().String("timezone", "author", "Time zone of the hour and weekday buckets: 'author' (each author's own offset), 'people' (zones from the people file) or an IANA zone such as 'Europe/Berlin'")
+	rootCmd.PersistentFlags().String("mailmap", "", "Additional .mailmap file applied on top of each repository's .mailmap")
+	rootCmd.PersistentFlags().String("output-dir", "", "Directory to write charts, reports and data files to (default: working directory)")
+	rootCmd.PersistentFlags().String("title", "", "Title of the HTML report")
+	rootCmd.PersistentFlags().String("timeline", "", "Also generate a timeline chart per 'day', 'week' or 'month'")
+	rootCmd.PersistentFlags().StringSlice("output-data", nil, "Also export the aggregated data: 'csv', 'json' or both")
+	rootCmd.PersistentFlags().IntP("jobs", "j", 0, "Number of repositories and commits to analyze in parallel (default: number of CPUs)")
+	rootCmd.PersistentFlags().Bool("keep-going", false, "Skip repositories that cannot be analyzed instead of stopping")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Stop the analysis after this duration, e.g. '10m' (default: no timeout)")
+	rootCmd.PersistentFlags().Bool("partial", false, "Generate charts from the data collected so far if the analysis is interrupted or times out")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Do not report progress during the analysis")
+	rootCmd.PersistentFlags().String("debug-addr", "", "Serve pprof and fgprof profiling data on this address, e.g. 'localhost:6060' (default: disabled)")
+	rootCmd.PersistentFlags().String("cpuprofile", "", "Write a CPU profile of the analysis to this file")
+	rootCmd.PersistentFlags().String("memprofile", "", "Write a heap profile to this file after the analysis")
+	rootCmd.PersistentFlags().String("trace", "", "Write an execution trace of the analysis to this file")
+	rootCmd.PersistentFlags().String("cache-dir", "", "Directory of the per-commit cache (default: user cache directory)")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "Do not read or write the per-commit cache")
+	rootCmd.PersistentFlags().StringSlice("include", nil, "Only count files matching these gitignore-style patterns (e.g. 'services/billing/**', '*.go')")
+	rootCmd.PersistentFlags().StringSlice("exclude", nil, "Do not count files matching these gitignore-style patterns")
+	rootCmd.PersistentFlags().StringSlice("exclude-classes", activity.FileClasses, "Files not counted in the line modes: 'vendored', 'generated', 'lockfile', 'binary', or 'none'")
+	rootCmd.PersistentFlags().String("merges", "include", "Merge commits to count: 'include', 'exclude' or 'only'")
+	rootCmd.PersistentFlags().Bool("first-parent", false, "Only follow the first parent of merge commits")
+	rootCmd.PersistentFlags().StringSlice("refs", nil, "Branches, tags or glob patterns (e.g. 'release/*') to analyze instead of HEAD")
+	rootCmd.PersistentFlags().Bool("all-branches", false, "Analyze all local and remote-tracking branches")
 
 	// Bind to viper for configuration management using MustBind
+	MustBind("config", rootCmd.PersistentFlags().Lookup("config"))
+	MustBind("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	MustBind("start", rootCmd.PersistentFlags().Lookup("start"))
 	MustBind("end", rootCmd.PersistentFlags().Lookup("end"))
+	MustBind("since-tag", rootCmd.PersistentFlags().Lookup("since-tag"))
+	MustBind("until-tag", rootCmd.PersistentFlags().Lookup("until-tag"))
 	MustBind("format", rootCmd.PersistentFlags().Lookup("format"))
-	MustBind("grouped", rootCmd.PersistentFlags().Lookup("grouped"))
 	MustBind("mode", rootCmd.PersistentFlags().Lookup("mode"))
 	MustBind("people", rootCmd.PersistentFlags().Lookup("people"))
 	MustBind("bars", rootCmd.PersistentFlags().Lookup("bars"))
+	MustBind("unknown", rootCmd.PersistentFlags().Lookup("unknown"))
+	MustBind("date-source", rootCmd.PersistentFlags().Lookup("date-source"))
+	MustBind("timezone", rootCmd.PersistentFlags().Lookup("timezone"))
+	MustBind("mailmap", rootCmd.PersistentFlags().Lookup("mailmap"))
+	MustBind("output-dir", rootCmd.PersistentFlags().Lookup("output-dir"))
+	MustBind("title", rootCmd.PersistentFlags().Lookup("title"))
+	MustBind("timeline", rootCmd.PersistentFlags().Lookup("timeline"))
+	MustBind("output-data", rootCmd.PersistentFlags().Lookup("output-data"))
+	MustBind("jobs", rootCmd.PersistentFlags().Lookup("jobs"))
+	MustBind("keep-going", rootCmd.PersistentFlags().Lookup("keep-going"))
+	MustBind("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	MustBind("partial", rootCmd.PersistentFlags().Lookup("partial"))
+	MustBind("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+	MustBind("debug-addr", rootCmd.PersistentFlags().Lookup("debug-addr"))
+	MustBind("cpuprofile", rootCmd.PersistentFlags().Lookup("cpuprofile"))
+	MustBind("memprofile", rootCmd.PersistentFlags().Lookup("memprofile"))
+	MustBind("trace", rootCmd.PersistentFlags().Lookup("trace"))
+	MustBind("cache-dir", rootCmd.PersistentFlags().Lookup("cache-dir"))
+	MustBind("no-cache", rootCmd.PersistentFlags().Lookup("no-cache"))
+	MustBind("include", rootCmd.PersistentFlags().Lookup("include"))
+	MustBind("exclude", rootCmd.PersistentFlags().Lookup("exclude"))
+	MustBind("exclude-classes", rootCmd.PersistentFlags().Lookup("exclude-classes"))
+	MustBind("merges", rootCmd.PersistentFlags().Lookup("merges"))
+	MustBind("first-parent", rootCmd.PersistentFlags().Lookup("first-parent"))
+	MustBind("refs", rootCmd.PersistentFlags().Lookup("refs"))
+	MustBind("all-branches", rootCmd.PersistentFlags().Lookup("all-branches"))
 }
@@ -0,0 +1,378 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// cacheVersion is stored in every cache file; files with another version are
+// ignored and rebuilt.
+const cacheVersion = 2
+
+const cacheExtension = ".gob"
+
+// CommitInfo is the part of a commit the analysis needs. Since commits never
+// change, it is cached by hash across runs.
+type CommitInfo struct {
+	Hash          plumbing.Hash
+	Parents       []plumbing.Hash
+	AuthorName    string
+	AuthorEmail   string
+	AuthorWhen    time.Time
+	CommitterWhen time.Time
+	HasStats      bool       // Whether Files has been computed
+	HasHeaders    bool       // Whether Files have been checked for generated headers
+	Files         []FileStat // Per-file diff stats against the first parent
+}
+
+// When returns the author date, or the committer date if source is
+// "committer".
+func (ci *CommitInfo) When(source string) time.Time {
+	if source == "committer" {
+		return ci.CommitterWhen
+	}
+	return ci.AuthorWhen
+}
+
+// IsMerge reports whether the commit has more than one parent.
+func (ci *CommitInfo) IsMerge() bool {
+	return len(ci.Parents) > 1
+}
+
+type FileStat struct {
+	Name      string
+	Added     int
+	Deleted   int
+	Generated bool // Whether the file has a generated header, if checked, see hasGeneratedHeader
+}
+
+func newCommitInfo(c *object.Commit) *CommitInfo {
+	return &CommitInfo{
+		Hash:          c.Hash,
+		Parents:       c.ParentHashes,
+		AuthorName:    c.Author.Name,
+		AuthorEmail:   c.Author.Email,
+		AuthorWhen:    c.Author.When,
+		CommitterWhen: c.Committer.When,
+	}
+}
+
+// newFileStats computes the diff stats of a commit against its first parent.
+func newFileStats(c *object.Commit) ([]FileStat, error) {
+	stats, err := c.Stats()
+	if err != nil {
+		return nil, fmt.Errorf("could not get diff stats: %w", err)
+	}
+
+	files := make([]FileStat, len(stats))
+	for i, stat := range stats {
+		files[i] = FileStat{Name: stat.Name, Added: stat.Addition, Deleted: stat.Deletion}
+	}
+	return files, nil
+}
+
+// checkGeneratedHeaders sets the Generated flag of the changed files of a
+// commit. Reading the files is costly, so it is only done when generated
+// files are excluded.
+func checkGeneratedHeaders(c *object.Commit, files []FileStat) error {
+	tree, err := c.Tree()
+	if err != nil {
+		return fmt.Errorf("could not read tree of %s: %w", c.Hash, err)
+	}
+
+	// Deleted files are looked up in the first parent
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return fmt.Errorf("could not read parent of %s: %w", c.Hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return fmt.Errorf("could not read tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	for i := range files {
+		name := files[i].Name
+		if _, to, ok := strings.Cut(name, " => "); ok {
+			name = to
+		}
+		file, err := tree.File(name)
+		if errors.Is(err, object.ErrFileNotFound) && parentTree != nil {
+			file, err = parentTree.File(name)
+		}
+		if err != nil {
+			continue // Submodules and the like have no contents
+		}
+		if files[i].Generated, err = hasGeneratedHeader(file); err != nil {
+			return fmt.Errorf("could not read %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// CommitCache holds the CommitInfo of one repository. A cache without a file
+// path only lives in memory for the current run.
+type CommitCache struct {
+	mu       sync.Mutex
+	path     string
+	repoPath string
+	commits  map[plumbing.Hash]*CommitInfo
+	dirty    bool
+}
+
+type cacheFile struct {
+	Version  int
+	RepoPath string
+	Commits  map[plumbing.Hash]*CommitInfo
+}
+
+// DefaultCacheDir returns the directory caches are stored in by default.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "git-activity")
+}
+
+// NewMemoryCache returns a cache that is never written to disk.
+func NewMemoryCache(repoPath string) *CommitCache {
+	return &CommitCache{repoPath: repoPath, commits: make(map[plumbing.Hash]*CommitInfo)}
+}
+
+// LoadCommitCache loads the cache of a repository from cacheDir. An empty
+// cacheDir disables persistence. Missing or outdated cache files yield an
+// empty cache.
+func LoadCommitCache(cacheDir, repoPath string) (*CommitCache, error) {
+	if cacheDir == "" {
+		return NewMemoryCache(repoPath), nil
+	}
+
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve repository path: %w", err)
+	}
+
+	cache := NewMemoryCache(absPath)
+	cache.path = filepath.Join(cacheDir, cacheFileName(absPath))
+
+	file, err := os.Open(cache.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open cache: %w", err)
+	}
+	defer file.Close()
+
+	var contents cacheFile
+	if err := gob.NewDecoder(file).Decode(&contents); err != nil || contents.Version != cacheVersion {
+		// Unreadable caches are simply rebuilt
+		return cache, nil
+	}
+	if contents.Commits != nil {
+		cache.commits = contents.Commits
+	}
+
+	// Mark the cache as used for pruning
+	now := time.Now()
+	_ = os.Chtimes(cache.path, now, now)
+
+	return cache, nil
+}
+
+// Save writes the cache to disk if it has changed since it was loaded.
+func (cc *CommitCache) Save() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.path == "" || !cc.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cc.path), 0o755); err != nil {
+		return fmt.Errorf("could not create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent runs never see a
+	// partially written cache.
+	tmp, err := os.CreateTemp(filepath.Dir(cc.path), "cache-*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	contents := cacheFile{Version: cacheVersion, RepoPath: cc.repoPath, Commits: cc.commits}
+	if err := gob.NewEncoder(tmp).Encode(contents); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cc.path); err != nil {
+		return fmt.Errorf("could not write cache: %w", err)
+	}
+
+	cc.dirty = false
+	return nil
+}
+
+// CommitInfo returns the cached info for hash, reading it from the
+// repository on a cache miss.
+func (cc *CommitCache) CommitInfo(repo *git.Repository, hash plumbing.Hash) (*CommitInfo, error) {
+	cc.mu.Lock()
+	info, ok := cc.commits[hash]
+	cc.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve commit %s: %w", hash, err)
+	}
+	info = newCommitInfo(commit)
+
+	// Another walk may have cached the commit meanwhile; keep its entry so
+	// that stats recorded on it are not lost
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cached, ok := cc.commits[hash]; ok {
+		return cached, nil
+	}
+	cc.commits[hash] = info
+	cc.dirty = true
+
+	return info, nil
+}
+
+// SetStats records the diff stats of a cached commit, and whether they have
+// been checked for generated headers.
+func (cc *CommitCache) SetStats(info *CommitInfo, files []FileStat, headers bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	info.Files = files
+	info.HasStats = true
+	info.HasHeaders = headers
+	cc.dirty = true
+}
+
+// CacheSummary describes a cache file on disk.
+type CacheSummary struct {
+	File     string
+	RepoPath string
+	Commits  int
+	Size     int64
+	LastUsed time.Time
+}
+
+// ListCaches returns a summary of all cache files in cacheDir, sorted by
+// repository path.
+func ListCaches(cacheDir string) ([]CacheSummary, error) {
+	entries, err := os.ReadDir(cacheDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read cache directory: %w", err)
+	}
+
+	var summaries []CacheSummary
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheExtension) {
+			continue
+		}
+
+		path := filepath.Join(cacheDir, entry.Name())
+		stat, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("could not stat %s: %w", path, err)
+		}
+
+		summary := CacheSummary{File: path, Size: stat.Size(), LastUsed: stat.ModTime()}
+		if file, err := os.Open(path); err == nil {
+			var contents cacheFile
+			if gob.NewDecoder(file).Decode(&contents) == nil {
+				summary.RepoPath = contents.RepoPath
+				summary.Commits = len(contents.Commits)
+			}
+			file.Close()
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].RepoPath < summaries[j].RepoPath
+	})
+	return summaries, nil
+}
+
+// PruneCaches removes caches of repositories that no longer exist, are
+// unreadable, or have not been used for longer than maxAge (if non-zero).
+// It returns the removed caches.
+func PruneCaches(cacheDir string, maxAge time.Duration) ([]CacheSummary, error) {
+	summaries, err := ListCaches(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []CacheSummary
+	for _, summary := range summaries {
+		stale := summary.RepoPath == ""
+		if !stale {
+			if _, err := os.Stat(summary.RepoPath); errors.Is(err, os.ErrNotExist) {
+				stale = true
+			}
+		}
+		if maxAge > 0 && time.Since(summary.LastUsed) > maxAge {
+			stale = true
+		}
+		if !stale {
+			continue
+		}
+
+		if err := os.Remove(summary.File); err != nil {
+			return removed, fmt.Errorf("could not remove %s: %w", summary.File, err)
+		}
+		removed = append(removed, summary)
+	}
+
+	return removed, nil
+}
+
+// ClearCaches removes all cache files in cacheDir.
+func ClearCaches(cacheDir string) ([]CacheSummary, error) {
+	summaries, err := ListCaches(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, summary := range summaries {
+		if err := os.Remove(summary.File); err != nil {
+			return summaries[:i], fmt.Errorf("could not remove %s: %w", summary.File, err)
+		}
+	}
+
+	return summaries, nil
+}
+
+func cacheFileName(absRepoPath string) string {
+	sum := sha256.Sum256([]byte(absRepoPath))
+	return hex.EncodeToString(sum[:16]) + cacheExtension
+}
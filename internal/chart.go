@@ -2,8 +2,10 @@ package internal
 
 import (
 	"fmt"
+	"image/color"
 	"log/slog"
 	"sort"
+	"strings"
 
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -30,14 +32,51 @@ func MonthLabels() []string {
 func WeekLabels() []string {
 	labels := make([]string, 53)
 	for i := 0; i < 53; i++ {
-		labels[i] = fmt.Sprintf("Week %d", i)
+		labels[i] = fmt.Sprintf("Week %d", i+1)
 	}
 	return labels
 }
 
+// Size of all charts
+const (
+	chartWidth  = 15 * vg.Inch
+	chartHeight = 6 * vg.Inch
+)
+
+// ChartRenderer outputs the charts built by GenerateCharts and
+// GenerateTimelineChart. File names carry the configured format and are only
+// meaningful to renderers writing one file per chart.
+type ChartRenderer interface {
+	StackedBarChart(data map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error
+	DivergingBarChart(additions, deletions map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error
+	PunchCard(data []int, title, filename string, c color.Color) error
+	TimelineChart(data map[string]map[string]int, periods []string, title, filename, yLabel string) error
+}
+
+// FileRenderer saves each chart as an image file, in the format given by the
+// extension of its file name.
+type FileRenderer struct{}
+
+func (FileRenderer) StackedBarChart(data map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error {
+	return CreateStackedBarChart(data, labels, title, filename, xLabel, yLabel)
+}
+
+func (FileRenderer) DivergingBarChart(additions, deletions map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error {
+	return CreateDivergingBarChart(additions, deletions, labels, title, filename, xLabel, yLabel)
+}
+
+func (FileRenderer) PunchCard(data []int, title, filename string, c color.Color) error {
+	return CreatePunchCard(data, title, filename, c)
+}
+
+func (FileRenderer) TimelineChart(data map[string]map[string]int, periods []string, title, filename, yLabel string) error {
+	return CreateTimelineChart(data, periods, title, filename, yLabel)
+}
+
 func GenerateCharts(
+	renderer ChartRenderer,
 	combinedActivity *CombinedCommitActivity,
-	grouped bool, mode, stacking, outputPrefix, format string,
+	mode, stacking, outputPrefix, format string,
 	aliases DeveloperAliases,
 ) error {
 	slog.Info("Generating charts", "output_prefix", outputPrefix, "format", format, "mode", mode, "stacking", stacking)
@@ -57,43 +96,9 @@ func GenerateCharts(
 	}
 
 	for _, category := range categories {
-		groupedData := make(map[string]map[string]int)
 		xLabel := category.xLabel
-		yLabel := "Commits"
-		if mode == "lines" {
-			yLabel = "Lines of Code"
-		}
-
-		switch stacking {
-		case "dev", "developer":
-			// Group by developer
-			for _, repoActivity := range combinedActivity.Repos {
-				data := category.activityKey(repoActivity.Activity)
-				groupedByDev := prepareGroupedData(data, "dev", repoActivity.RepoName, category.labels)
-				mergeGroupedData(groupedData, groupedByDev)
-			}
-
-		case "repo":
-			// Group by repository
-			for _, repoActivity := range combinedActivity.Repos {
-				data := category.activityKey(repoActivity.Activity)
-				groupedByRepo := prepareGroupedData(data, "repo", repoActivity.RepoName, category.labels)
-				mergeGroupedData(groupedData, groupedByRepo)
-			}
-
-		default:
-			// Flat mode: aggregate everything under a single group
-			flatGroup := "All"
-			groupedData[flatGroup] = make(map[string]int)
-			for _, repoActivity := range combinedActivity.Repos {
-				data := category.activityKey(repoActivity.Activity)
-				for _, values := range data {
-					for i, value := range values {
-						groupedData[flatGroup][category.labels[i]] += value
-					}
-				}
-			}
-		}
+		yLabel := ValueLabel(mode)
+		groupedData := groupCategory(combinedActivity, category.activityKey, category.labels, stacking)
 
 		// Generate chart title and filename
 		chartTitle := category.title
@@ -104,10 +109,10 @@ func GenerateCharts(
 		}
 
 		// Generate chart for the current category
-		err := CreateStackedBarChart(
+		err := renderer.StackedBarChart(
 			groupedData,
 			category.labels,
-			chartTitle,
+			decorateTitle(combinedActivity, chartTitle),
 			fileName,
 			xLabel,
 			yLabel,
@@ -115,9 +120,93 @@ func GenerateCharts(
 		if err != nil {
 			return fmt.Errorf("error creating chart for %s: %w", category.title, err)
 		}
+
+		if !IsLinesMode(mode) {
+			continue
+		}
+
+		// Diverging chart with additions above and deletions below the axis
+		additions := groupCategory(combinedActivity, func(a *CommitActivity) map[string][]int {
+			if a.Additions == nil {
+				return nil
+			}
+			return category.activityKey(a.Additions)
+		}, category.labels, stacking)
+		deletions := groupCategory(combinedActivity, func(a *CommitActivity) map[string][]int {
+			if a.Deletions == nil {
+				return nil
+			}
+			return category.activityKey(a.Deletions)
+		}, category.labels, stacking)
+
+		chartTitle = strings.Replace(chartTitle, "Activity", "Lines Added and Deleted", 1)
+		fileName = fmt.Sprintf("%s_%s_diverging.%s", outputPrefix, category.filename, format)
+		if stacking != "" {
+			fileName = fmt.Sprintf("%s_%s_diverging_%s.%s", outputPrefix, category.filename, stacking, format)
+		}
+
+		err = renderer.DivergingBarChart(additions, deletions, category.labels, decorateTitle(combinedActivity, chartTitle), fileName, xLabel, "Lines Added / Deleted")
+		if err != nil {
+			return fmt.Errorf("error creating diverging chart for %s: %w", category.title, err)
+		}
 	}
 
-	return nil
+	return generatePunchCards(renderer, combinedActivity, mode, stacking, outputPrefix, format)
+}
+
+// decorateTitle marks the title of a chart if the analysis was interrupted and
+// its data is incomplete, and adds the date window as a second line.
+func decorateTitle(combinedActivity *CombinedCommitActivity, title string) string {
+	if combinedActivity.Partial {
+		title += " [partial data]"
+	}
+	if combinedActivity.Window != "" {
+		title += "\n" + combinedActivity.Window
+	}
+	return title
+}
+
+// groupCategory sums the buckets selected by activityKey per stacking group:
+// per developer, per repository, or into a single "All" group.
+func groupCategory(
+	combinedActivity *CombinedCommitActivity,
+	activityKey func(activity *CommitActivity) map[string][]int,
+	labels []string, stacking string,
+) map[string]map[string]int {
+	groupedData := make(map[string]map[string]int)
+
+	switch stacking {
+	case "dev", "developer":
+		// Group by developer
+		for _, repoActivity := range combinedActivity.Repos {
+			data := activityKey(repoActivity.Activity)
+			groupedByDev := prepareGroupedData(data, "dev", repoActivity.RepoName, labels)
+			mergeGroupedData(groupedData, groupedByDev)
+		}
+
+	case "repo", "repository":
+		// Group by repository
+		for _, repoActivity := range combinedActivity.Repos {
+			data := activityKey(repoActivity.Activity)
+			groupedByRepo := prepareGroupedData(data, "repo", repoActivity.RepoName, labels)
+			mergeGroupedData(groupedData, groupedByRepo)
+		}
+
+	default:
+		// Flat mode: aggregate everything under a single group
+		flatGroup := "All"
+		groupedData[flatGroup] = make(map[string]int)
+		for _, repoActivity := range combinedActivity.Repos {
+			data := activityKey(repoActivity.Activity)
+			for _, values := range data {
+				for i, value := range values {
+					groupedData[flatGroup][labels[i]] += value
+				}
+			}
+		}
+	}
+
+	return groupedData
 }
 
 // CreateStackedBarChart creates a stacked bar chart from the given data
@@ -126,6 +215,14 @@ func CreateStackedBarChart(
 	labels []string,
 	title, filename, xLabel, yLabel string,
 ) error {
+	p, err := newStackedBarChart(data, labels, title, xLabel, yLabel)
+	if err != nil {
+		return err
+	}
+	return p.Save(chartWidth, chartHeight, filename)
+}
+
+func newStackedBarChart(data map[string]map[string]int, labels []string, title, xLabel, yLabel string) (*plot.Plot, error) {
 	p := plot.New()
 	p.Title.Text = title
 	p.X.Label.Text = xLabel
@@ -143,7 +240,7 @@ func CreateStackedBarChart(
 
 		bars, err := plotter.NewBarChart(values, barWidth)
 		if err != nil {
-			return fmt.Errorf("could not create bar chart for %s: %w", category, err)
+			return nil, fmt.Errorf("could not create bar chart for %s: %w", category, err)
 		}
 
 		bars.LineStyle.Width = vg.Length(0)
@@ -162,7 +259,81 @@ func CreateStackedBarChart(
 	p.Legend.Top = true
 	p.NominalX(labels...)
 
-	return p.Save(15*vg.Inch, 6*vg.Inch, filename)
+	return p, nil
+}
+
+// CreateDivergingBarChart creates a stacked bar chart with additions above
+// and deletions below the X axis. Both stacks use the same color per group.
+func CreateDivergingBarChart(
+	additions, deletions map[string]map[string]int,
+	labels []string,
+	title, filename, xLabel, yLabel string,
+) error {
+	p, err := newDivergingBarChart(additions, deletions, labels, title, xLabel, yLabel)
+	if err != nil {
+		return err
+	}
+	return p.Save(chartWidth, chartHeight, filename)
+}
+
+func newDivergingBarChart(
+	additions, deletions map[string]map[string]int,
+	labels []string,
+	title, xLabel, yLabel string,
+) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = xLabel
+	p.Y.Label.Text = yLabel
+
+	barWidth := vg.Points(20)
+	groups := make(map[string]bool)
+	for group := range additions {
+		groups[group] = true
+	}
+	for group := range deletions {
+		groups[group] = true
+	}
+
+	var previousAdded, previousDeleted *plotter.BarChart
+	for j, group := range sortedKeys(groups) {
+		added := make(plotter.Values, len(labels))
+		deleted := make(plotter.Values, len(labels))
+		for i, label := range labels {
+			added[i] = float64(additions[group][label])
+			deleted[i] = -float64(deletions[group][label])
+		}
+
+		addedBars, err := plotter.NewBarChart(added, barWidth)
+		if err != nil {
+			return nil, fmt.Errorf("could not create bar chart for %s: %w", group, err)
+		}
+		deletedBars, err := plotter.NewBarChart(deleted, barWidth)
+		if err != nil {
+			return nil, fmt.Errorf("could not create bar chart for %s: %w", group, err)
+		}
+
+		for _, bars := range []*plotter.BarChart{addedBars, deletedBars} {
+			bars.LineStyle.Width = vg.Length(0)
+			bars.Color = colorPalette[j%len(colorPalette)]
+		}
+
+		if previousAdded != nil {
+			addedBars.StackOn(previousAdded)
+			deletedBars.StackOn(previousDeleted)
+		}
+
+		p.Add(addedBars, deletedBars)
+		p.Legend.Add(group, addedBars)
+
+		previousAdded, previousDeleted = addedBars, deletedBars
+	}
+
+	p.Add(plotter.NewGrid())
+	p.Legend.Top = true
+	p.NominalX(labels...)
+
+	return p, nil
 }
 
 func sortedKeys[K ~string, V any](m map[K]V) []K {
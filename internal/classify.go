@@ -0,0 +1,312 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// File classes that can be excluded from line counts.
+const (
+	ClassVendored  = "vendored"
+	ClassGenerated = "generated"
+	ClassLockfile  = "lockfile"
+	ClassBinary    = "binary"
+)
+
+// FileClasses lists all file classes; they are all excluded by default.
+var FileClasses = []string{ClassVendored, ClassGenerated, ClassLockfile, ClassBinary}
+
+// vendoredDirs are directories whose contents are considered vendored.
+var vendoredDirs = map[string]bool{
+	"vendor":           true,
+	"node_modules":     true,
+	"third_party":      true,
+	"bower_components": true,
+	"Pods":             true,
+}
+
+// generatedPatterns are file name patterns of generated files.
+var generatedPatterns = []string{
+	"*.pb.go", "*.pb.gw.go", "*_pb2.py", "*_pb2_grpc.py", "*.pb.cc", "*.pb.h",
+	"*_generated.go", "*.gen.go", "zz_generated*",
+	"*.min.js", "*.min.css", "*.js.map", "*.css.map",
+}
+
+// lockfiles are file names of dependency lockfiles.
+var lockfiles = map[string]bool{
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lockb":           true,
+	"go.sum":              true,
+	"Cargo.lock":          true,
+	"Gemfile.lock":        true,
+	"poetry.lock":         true,
+	"Pipfile.lock":        true,
+	"composer.lock":       true,
+	"mix.lock":            true,
+	"pubspec.lock":        true,
+	"Podfile.lock":        true,
+	"flake.lock":          true,
+}
+
+// binaryExtensions are extensions of binary files. Git does not diff files it
+// detects as binary anyway, but the detection only looks at the first bytes.
+var binaryExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true, ".ico": true, ".webp": true,
+	".pdf": true, ".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true, ".7z": true, ".rar": true, ".jar": true,
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".a": true, ".o": true, ".class": true, ".pyc": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".mp3": true, ".mp4": true, ".wav": true, ".mov": true, ".avi": true,
+}
+
+// generatedHeaderSize is the number of bytes searched for a generated header.
+const generatedHeaderSize = 1024
+
+// FileClassifier decides which files are excluded from line counts, based on
+// built-in rules and the linguist-generated and linguist-vendored attributes
+// of the repository's .gitattributes files.
+type FileClassifier struct {
+	exclude    map[string]bool
+	attributes gitattributes.Matcher
+}
+
+// NewFileClassifier returns a classifier excluding the given classes, or nil
+// if there are none.
+func NewFileClassifier(classes []string) *FileClassifier {
+	if len(classes) == 0 {
+		return nil
+	}
+
+	exclude := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		exclude[class] = true
+	}
+	return &FileClassifier{exclude: exclude}
+}
+
+// ForRepository returns a copy of the classifier that honors the attributes
+// of repo, as of HEAD.
+func (fc *FileClassifier) ForRepository(repo *git.Repository) (*FileClassifier, error) {
+	if fc == nil {
+		return nil, nil
+	}
+
+	attributes, err := loadRepoAttributes(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier := *fc
+	if len(attributes) > 0 {
+		classifier.attributes = gitattributes.NewMatcher(attributes)
+	}
+	return &classifier, nil
+}
+
+// Classify returns the class of a file of a commit's diff stats, or "" if it
+// is a regular file. Renames are classified by their new name.
+func (fc *FileClassifier) Classify(stat FileStat) string {
+	name := stat.Name
+	if _, to, ok := strings.Cut(name, " => "); ok {
+		name = to
+	}
+
+	// Attributes override the built-in rules in both directions
+	vendored, generated := attributeOverride(fc, name)
+
+	if vendored == nil && isVendoredPath(name) || vendored != nil && *vendored {
+		return ClassVendored
+	}
+	if generated == nil && (stat.Generated || isGeneratedName(name)) || generated != nil && *generated {
+		return ClassGenerated
+	}
+	if lockfiles[path.Base(name)] {
+		return ClassLockfile
+	}
+	if binaryExtensions[strings.ToLower(path.Ext(name))] {
+		return ClassBinary
+	}
+	return ""
+}
+
+// excludesGenerated reports whether generated files are excluded, which
+// requires checking the changed files for generated headers.
+func (fc *FileClassifier) excludesGenerated() bool {
+	return fc != nil && fc.exclude[ClassGenerated]
+}
+
+// Excluded reports whether a file is excluded from line counts. A nil
+// classifier excludes nothing.
+func (fc *FileClassifier) Excluded(stat FileStat) bool {
+	if fc == nil {
+		return false
+	}
+	class := fc.Classify(stat)
+	return class != "" && fc.exclude[class]
+}
+
+// attributeOverride returns the linguist-vendored and linguist-generated
+// attributes of name, or nil where they are not specified.
+func attributeOverride(fc *FileClassifier, name string) (vendored, generated *bool) {
+	if fc == nil || fc.attributes == nil {
+		return nil, nil
+	}
+
+	parts := strings.Split(name, "/")
+	return fc.attribute(parts, "linguist-vendored"), fc.attribute(parts, "linguist-generated")
+}
+
+// attribute returns a single attribute of the file at path. The matcher only
+// stops at the line with the highest priority once all requested attributes
+// are found, and otherwise lets earlier lines override it, so attributes are
+// looked up one at a time.
+func (fc *FileClassifier) attribute(path []string, name string) *bool {
+	attrs, _ := fc.attributes.Match(path, []string{name})
+	return attributeValue(attrs[name])
+}
+
+func attributeValue(attr gitattributes.Attribute) *bool {
+	if attr == nil {
+		return nil
+	}
+
+	var value bool
+	switch {
+	case attr.IsSet():
+		value = true
+	case attr.IsUnset():
+		value = false
+	case attr.IsValueSet():
+		value = attr.Value() != "false"
+	default:
+		return nil
+	}
+	return &value
+}
+
+func isVendoredPath(name string) bool {
+	dirs := strings.Split(name, "/")
+	for _, dir := range dirs[:len(dirs)-1] {
+		if vendoredDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+func isGeneratedName(name string) bool {
+	base := path.Base(name)
+	for _, pattern := range generatedPatterns {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGeneratedHeader reports whether the beginning of a file marks it as
+// generated, e.g. "// Code generated by protoc-gen-go. DO NOT EDIT." or
+// "@generated".
+func hasGeneratedHeader(file *object.File) (bool, error) {
+	reader, err := file.Reader()
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	header := make([]byte, generatedHeaderSize)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	for _, line := range strings.Split(string(header[:n]), "\n") {
+		if strings.Contains(line, "Code generated") && strings.Contains(line, "DO NOT EDIT") {
+			return true, nil
+		}
+		if strings.Contains(line, "@generated") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loadRepoAttributes reads all .gitattributes files of the HEAD tree, in
+// ascending order of priority.
+func loadRepoAttributes(repo *git.Repository) ([]gitattributes.MatchAttribute, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, nil // Empty repository
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("could not read HEAD commit: %w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("could not read HEAD tree: %w", err)
+	}
+
+	type attributesFile struct {
+		domain     []string
+		attributes []gitattributes.MatchAttribute
+	}
+	var files []attributesFile
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not walk HEAD tree: %w", err)
+		}
+		if path.Base(name) != ".gitattributes" || !entry.Mode.IsFile() {
+			continue
+		}
+
+		file, err := tree.TreeEntryFile(&entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not open %s: %w", name, err)
+		}
+		contents, err := file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", name, err)
+		}
+
+		var domain []string
+		if dir := path.Dir(name); dir != "." {
+			domain = strings.Split(dir, "/")
+		}
+
+		// Macros may only be defined at the top level
+		attributes, err := gitattributes.ReadAttributes(strings.NewReader(contents), domain, len(domain) == 0)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", name, err)
+		}
+		files = append(files, attributesFile{domain: domain, attributes: attributes})
+	}
+
+	// Deeper files take precedence
+	sort.SliceStable(files, func(i, j int) bool {
+		return len(files[i].domain) < len(files[j].domain)
+	})
+
+	var attributes []gitattributes.MatchAttribute
+	for _, file := range files {
+		attributes = append(attributes, file.attributes...)
+	}
+	return attributes, nil
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestFileClassifierClassify(t *testing.T) {
+	classifier := NewFileClassifier(FileClasses)
+
+	tests := []struct {
+		stat FileStat
+		want string
+	}{
+		{FileStat{Name: "main.go"}, ""},
+		{FileStat{Name: "vendor/github.com/x/a.go"}, ClassVendored},
+		{FileStat{Name: "web/node_modules/x/index.js"}, ClassVendored},
+		{FileStat{Name: "vendor.go"}, ""},
+		{FileStat{Name: "vendor/go.sum"}, ClassVendored},
+		{FileStat{Name: "api/service.pb.go"}, ClassGenerated},
+		{FileStat{Name: "static/app.min.js"}, ClassGenerated},
+		{FileStat{Name: "pkg/zz_generated.deepcopy.go"}, ClassGenerated},
+		{FileStat{Name: "model.go", Generated: true}, ClassGenerated},
+		{FileStat{Name: "go.sum"}, ClassLockfile},
+		{FileStat{Name: "web/package-lock.json"}, ClassLockfile},
+		{FileStat{Name: "img/Logo.PNG"}, ClassBinary},
+		{FileStat{Name: "notes.txt => vendor/notes.txt"}, ClassVendored},
+		{FileStat{Name: "vendor/notes.txt => notes.txt"}, ""},
+	}
+
+	for _, test := range tests {
+		if got := classifier.Classify(test.stat); got != test.want {
+			t.Errorf("Classify(%+v) = %q, want %q", test.stat, got, test.want)
+		}
+	}
+}
+
+func TestFileClassifierExcluded(t *testing.T) {
+	classifier := NewFileClassifier([]string{ClassGenerated})
+
+	for name, want := range map[string]bool{
+		"api/service.pb.go": true,
+		"vendor/x/a.go":     false,
+		"go.sum":            false,
+		"main.go":           false,
+	} {
+		if got := classifier.Excluded(FileStat{Name: name}); got != want {
+			t.Errorf("Excluded(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !classifier.excludesGenerated() {
+		t.Error("excludesGenerated() = false, want true")
+	}
+
+	var none *FileClassifier
+	if NewFileClassifier(nil) != nil {
+		t.Error("NewFileClassifier(nil) is not nil")
+	}
+	if none.Excluded(FileStat{Name: "api/service.pb.go"}) || none.excludesGenerated() {
+		t.Error("nil classifier excludes files")
+	}
+}
+
+func TestFileClassifierAttributes(t *testing.T) {
+	repo := newTestRepository(t, map[string]string{
+		".gitattributes": `vendor/keep/** -linguist-vendored
+docs/** linguist-vendored
+api/*.go linguist-generated
+*.pb.go linguist-generated=false
+*.js -linguist-generated
+`,
+		"web/.gitattributes": "*.js linguist-generated\n",
+		"main.go":            "package main\n",
+	})
+
+	classifier, err := NewFileClassifier(FileClasses).ForRepository(repo)
+	if err != nil {
+		t.Fatalf("ForRepository failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"vendor/other/a.go", ClassVendored},
+		{"vendor/keep/a.go", ""},
+		{"docs/guide.md", ClassVendored},
+		{"api/handler.go", ClassGenerated},
+		{"api/service.pb.go", ""},
+		{"app.js", ""},
+		{"app.min.js", ""},
+		{"web/app.js", ClassGenerated},
+		{"main.go", ""},
+	}
+
+	for _, test := range tests {
+		if got := classifier.Classify(FileStat{Name: test.name}); got != test.want {
+			t.Errorf("Classify(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// newTestRepository commits files to a new repository in a temporary
+// directory.
+func newTestRepository(t *testing.T, files map[string]string) *git.Repository {
+	t.Helper()
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("could not create repository: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("could not open worktree: %v", err)
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("could not create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("could not add %s: %v", name, err)
+		}
+	}
+
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	if _, err := worktree.Commit("Add files", &git.CommitOptions{Author: signature}); err != nil {
+		t.Fatalf("could not commit: %v", err)
+	}
+	return repo
+}
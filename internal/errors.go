@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RepoError is the failure to analyze one repository.
+type RepoError struct {
+	RepoPath string
+	Err      error
+}
+
+func (e *RepoError) Error() string {
+	return fmt.Sprintf("%s: %v", e.RepoPath, e.Err)
+}
+
+func (e *RepoError) Unwrap() error {
+	return e.Err
+}
+
+// AnalysisError lists the repositories that could not be analyzed, in the
+// order they were given.
+type AnalysisError struct {
+	Failed []*RepoError
+}
+
+func (e *AnalysisError) Error() string {
+	if len(e.Failed) == 1 {
+		return fmt.Sprintf("could not analyze %s", e.Failed[0])
+	}
+
+	messages := make([]string, len(e.Failed))
+	for i, failure := range e.Failed {
+		messages[i] = failure.Error()
+	}
+	return fmt.Sprintf("could not analyze %d repositories: %s", len(e.Failed), strings.Join(messages, "; "))
+}
+
+func (e *AnalysisError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, failure := range e.Failed {
+		errs[i] = failure
+	}
+	return errs
+}
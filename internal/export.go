@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// ExportSchemaVersion is bumped whenever the exported data layout changes in
+// an incompatible way.
+const ExportSchemaVersion = 2
+
+// ExportMetadata describes the run that produced the exported data.
+type ExportMetadata struct {
+	Mode  string
+	Start string // Date (DayLayout) or RFC 3339 timestamp; empty if open
+	End   string // Date (DayLayout) or RFC 3339 timestamp, inclusive; empty if open
+}
+
+// ExportedActivity is the JSON representation of a CombinedCommitActivity.
+type ExportedActivity struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Mode          string               `json:"mode"`
+	Start         string               `json:"start,omitempty"`
+	End           string               `json:"end,omitempty"`
+	Partial       bool                 `json:"partial,omitempty"` // The analysis was interrupted
+	Repositories  []ExportedRepository `json:"repositories"`
+}
+
+type ExportedRepository struct {
+	Name       string              `json:"name"`
+	Developers []ExportedDeveloper `json:"developers"`
+}
+
+type ExportedDeveloper struct {
+	Name    string `json:"name"`
+	Weekday []int  `json:"weekday"` // 0 = Sunday
+	Hour    []int  `json:"hour"`    // 0-23
+	Month   []int  `json:"month"`   // 0 = January
+	Week    []int  `json:"week"`    // index = ISO week - 1
+	// Joint weekday/hour activity, indexed by weekday*24 + hour
+	WeekdayHour []int `json:"weekday_hour"`
+	// Activity per calendar day ("2006-01-02"), days without activity omitted
+	Days map[string]int `json:"days"`
+}
+
+// exportDimensions lists the buckets of a CommitActivity in export order.
+var exportDimensions = []struct {
+	name   string
+	data   func(a *CommitActivity) map[string][]int
+	labels func() []string
+}{
+	{"weekday", func(a *CommitActivity) map[string][]int { return a.Weekdays }, WeekdayLabels},
+	{"hour", func(a *CommitActivity) map[string][]int { return a.Hours }, HourLabels},
+	{"month", func(a *CommitActivity) map[string][]int { return a.Months }, MonthLabels},
+	{"week", func(a *CommitActivity) map[string][]int { return a.Weeks }, WeekLabels},
+	{"weekday_hour", func(a *CommitActivity) map[string][]int { return a.WeekdayHours }, WeekdayHourLabels},
+}
+
+// ExportData writes the aggregated activity to "<outputPrefix>_activity.<format>"
+// for every requested format ("csv" or "json").
+func ExportData(combinedActivity *CombinedCommitActivity, meta ExportMetadata, outputPrefix string, formats []string) error {
+	for _, format := range formats {
+		var write func(w io.Writer) error
+		switch format {
+		case "csv":
+			write = func(w io.Writer) error { return ExportCSV(w, combinedActivity) }
+		case "json":
+			write = func(w io.Writer) error { return ExportJSON(w, combinedActivity, meta) }
+		default:
+			return fmt.Errorf("unsupported data format '%s'", format)
+		}
+
+		fileName := fmt.Sprintf("%s_activity.%s", outputPrefix, format)
+		slog.Info("Exporting data", "file", fileName, "format", format)
+
+		file, err := os.Create(fileName)
+		if err != nil {
+			return fmt.Errorf("could not create %s: %w", fileName, err)
+		}
+		if err := write(file); err != nil {
+			file.Close()
+			return fmt.Errorf("could not write %s: %w", fileName, err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("could not write %s: %w", fileName, err)
+		}
+	}
+
+	return nil
+}
+
+// ExportCSV writes the activity in long format, one row per non-zero bucket:
+//
+//	repository,developer,dimension,bucket,label,value
+func ExportCSV(w io.Writer, combinedActivity *CombinedCommitActivity) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"repository", "developer", "dimension", "bucket", "label", "value"}); err != nil {
+		return err
+	}
+
+	for _, repo := range combinedActivity.Repos {
+		for _, dimension := range exportDimensions {
+			data := dimension.data(repo.Activity)
+			labels := dimension.labels()
+			for _, developer := range sortedKeys(data) {
+				for bucket, value := range data[developer] {
+					if value == 0 {
+						continue
+					}
+					label := ""
+					if bucket < len(labels) {
+						label = labels[bucket]
+					}
+					record := []string{repo.RepoName, developer, dimension.name, strconv.Itoa(bucket), label, strconv.Itoa(value)}
+					if err := writer.Write(record); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		for _, developer := range sortedKeys(repo.Activity.Days) {
+			days := repo.Activity.Days[developer]
+			for _, day := range sortedKeys(days) {
+				if days[day] == 0 {
+					continue
+				}
+				record := []string{repo.RepoName, developer, "day", day, day, strconv.Itoa(days[day])}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportJSON writes the activity as an ExportedActivity document.
+func ExportJSON(w io.Writer, combinedActivity *CombinedCommitActivity, meta ExportMetadata) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewExportedActivity(combinedActivity, meta))
+}
+
+// NewExportedActivity converts the activity into its exported representation
+// with developers sorted by name.
+func NewExportedActivity(combinedActivity *CombinedCommitActivity, meta ExportMetadata) ExportedActivity {
+	exported := ExportedActivity{
+		SchemaVersion: ExportSchemaVersion,
+		Mode:          meta.Mode,
+		Partial:       combinedActivity.Partial,
+		Start:         meta.Start,
+		End:           meta.End,
+		Repositories:  []ExportedRepository{},
+	}
+
+	for _, repo := range combinedActivity.Repos {
+		activity := repo.Activity
+		developers := make(map[string]bool)
+		for _, dimension := range exportDimensions {
+			for developer := range dimension.data(activity) {
+				developers[developer] = true
+			}
+		}
+		for developer := range activity.Days {
+			developers[developer] = true
+		}
+
+		exportedRepo := ExportedRepository{Name: repo.RepoName, Developers: []ExportedDeveloper{}}
+		for _, developer := range sortedKeys(developers) {
+			exportedRepo.Developers = append(exportedRepo.Developers, ExportedDeveloper{
+				Name:        developer,
+				Weekday:     bucketsOrZero(activity.Weekdays[developer], 7),
+				Hour:        bucketsOrZero(activity.Hours[developer], 24),
+				Month:       bucketsOrZero(activity.Months[developer], 12),
+				Week:        bucketsOrZero(activity.Weeks[developer], 53),
+				WeekdayHour: bucketsOrZero(activity.WeekdayHours[developer], 7*24),
+				Days:        daysOrEmpty(activity.Days[developer]),
+			})
+		}
+		exported.Repositories = append(exported.Repositories, exportedRepo)
+	}
+
+	return exported
+}
+
+func daysOrEmpty(days map[string]int) map[string]int {
+	nonZero := make(map[string]int, len(days))
+	for day, value := range days {
+		if value != 0 {
+			nonZero[day] = value
+		}
+	}
+	return nonZero
+}
+
+func bucketsOrZero(values []int, size int) []int {
+	if values == nil {
+		return make([]int, size)
+	}
+	return values
+}
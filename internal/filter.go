@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// PathFilter selects files by gitignore-style patterns. A file matches if it
+// matches any include pattern (or no include patterns are given) and does not
+// match the exclude patterns. Patterns may be negated with "!".
+type PathFilter struct {
+	include gitignore.Matcher
+	exclude gitignore.Matcher
+}
+
+// NewPathFilter returns a filter for the given patterns, or nil if there are
+// none so that callers can skip filtering altogether.
+func NewPathFilter(include, exclude []string) *PathFilter {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	filter := &PathFilter{}
+	if len(include) > 0 {
+		filter.include = newPatternMatcher(include)
+	}
+	if len(exclude) > 0 {
+		filter.exclude = newPatternMatcher(exclude)
+	}
+	return filter
+}
+
+// Match reports whether the file at path passes the filter. A nil filter
+// matches everything.
+func (f *PathFilter) Match(path string) bool {
+	if f == nil {
+		return true
+	}
+
+	parts := strings.Split(path, "/")
+	if f.include != nil && !f.include.Match(parts, false) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.Match(parts, false) {
+		return false
+	}
+	return true
+}
+
+// MatchStat reports whether a file of a commit's diff stats passes the
+// filter. Renames are recorded as "old => new" and match if either side does.
+func (f *PathFilter) MatchStat(stat FileStat) bool {
+	if f == nil {
+		return true
+	}
+
+	if from, to, ok := strings.Cut(stat.Name, " => "); ok {
+		return f.Match(from) || f.Match(to)
+	}
+	return f.Match(stat.Name)
+}
+
+func newPatternMatcher(patterns []string) gitignore.Matcher {
+	parsed := make([]gitignore.Pattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		parsed = append(parsed, gitignore.ParsePattern(pattern, nil))
+	}
+	return gitignore.NewMatcher(parsed)
+}
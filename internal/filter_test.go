@@ -0,0 +1,75 @@
+package internal
+
+import "testing"
+
+func TestPathFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		paths   map[string]bool
+	}{
+		{
+			"directory at any depth", []string{"src/"}, nil,
+			map[string]bool{"src/a.go": true, "src/x/b.go": true, "lib/src/a.go": true, "src": false, "docs/a.md": false},
+		},
+		{
+			"anchored directory", []string{"/src/"}, nil,
+			map[string]bool{"src/a.go": true, "lib/src/a.go": false},
+		},
+		{
+			"include and exclude", []string{"*.go"}, []string{"*_test.go"},
+			map[string]bool{"a.go": true, "x/a.go": true, "x/a_test.go": false, "README.md": false},
+		},
+		{
+			"negated include", []string{"docs/**", "!docs/internal/"}, nil,
+			map[string]bool{"docs/a.md": true, "docs/internal/b.md": false, "a.md": false},
+		},
+		{
+			"negated exclude", nil, []string{"vendor/", "!vendor/keep/"},
+			map[string]bool{"vendor/x.go": false, "vendor/keep/y.go": true, "main.go": true},
+		},
+		{
+			"blank patterns are ignored", []string{" ", "*.go"}, []string{""},
+			map[string]bool{"a.go": true, "a.md": false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filter := NewPathFilter(test.include, test.exclude)
+			for path, want := range test.paths {
+				if got := filter.Match(path); got != want {
+					t.Errorf("Match(%q) = %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPathFilterNil(t *testing.T) {
+	filter := NewPathFilter(nil, nil)
+	if filter != nil {
+		t.Fatalf("NewPathFilter(nil, nil) = %v, want nil", filter)
+	}
+	if !filter.Match("a.go") || !filter.MatchStat(FileStat{Name: "a.go"}) {
+		t.Error("nil filter does not match everything")
+	}
+}
+
+func TestPathFilterMatchStat(t *testing.T) {
+	filter := NewPathFilter([]string{"src/", "*.go"}, nil)
+
+	for name, want := range map[string]bool{
+		"src/a.txt":              true,
+		"a.txt":                  false,
+		"old/a.txt => src/a.txt": true,
+		"src/a.txt => old/a.txt": true,
+		"a.go => b.md":           true,
+		"a.md => b.md":           false,
+	} {
+		if got := filter.MatchStat(FileStat{Name: name}); got != want {
+			t.Errorf("MatchStat(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
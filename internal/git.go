@@ -1,44 +1,138 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	git "github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 type DeveloperAliases map[string]string
 
+// DeveloperZones maps developer names to the time zone they work in.
+type DeveloperZones map[string]*time.Location
+
+// AnalyzeOptions controls which history is walked and how it is counted.
+type AnalyzeOptions struct {
+	Start       time.Time        // Only count commits from this instant on, if set
+	End         time.Time        // Only count commits up to and including this instant, if set
+	StartDay    string           // Only count commits from this day (DayLayout) on, by their local date
+	EndDay      string           // Only count commits up to and including this day (DayLayout), by their local date
+	SinceTag    string           // Only count commits after the tagged commit; replaces Start per repository
+	UntilTag    string           // Only count commits up to the tagged commit; replaces End per repository
+	Mode        string           // "commits", "lines", "added", "deleted" or "net"
+	Aliases     DeveloperAliases // Email -> developer name
+	Location    *time.Location   // Zone commit times are converted to; the author's own offset if nil
+	Zones       DeveloperZones   // Per-developer zones, taking precedence over Location
+	Refs        []string         // Branches, tags, revisions or glob patterns; HEAD if empty
+	AllBranches bool             // Walk every local and remote-tracking branch
+	FirstParent bool             // Only follow the first parent of merge commits
+	Merges      string           // "include", "exclude" or "only" merge commits
+	DateSource  string           // "author" or "committer" date used for filtering and bucketing
+	Mailmap     *Mailmap         // Extra entries applied on top of each repository's .mailmap
+	Unknown     string           // Policy for authors missing from Aliases, see resolveDeveloper
+	Jobs        int              // Number of parallel workers; number of CPUs if zero
+	KeepGoing   bool             // Skip repositories that fail instead of stopping
+	Progress    *ProgressTracker // Receives the number of walked commits; may be nil
+	CacheDir    string           // Directory of the per-commit cache; caching is disabled if empty
+	Paths       *PathFilter      // Files to consider; all files if nil
+	Classifier  *FileClassifier  // Files excluded from line counts; none if nil
+}
+
 type RepoCommitActivity struct {
 	RepoName string
 	Activity *CommitActivity
 }
 
 type CombinedCommitActivity struct {
-	Repos []RepoCommitActivity
+	Repos   []RepoCommitActivity
+	Partial bool   // Whether the analysis was interrupted before it completed
+	Window  string // Description of the analyzed date window, if restricted
 }
 
 func (cca *CombinedCommitActivity) Add(repoName string, activity *CommitActivity) {
 	cca.Repos = append(cca.Repos, RepoCommitActivity{RepoName: repoName, Activity: activity})
 }
 
+// UnmappedIdentities returns the commit counts of all authors that were not
+// found in the people file, summed over all repositories.
+func (cca *CombinedCommitActivity) UnmappedIdentities() map[string]int {
+	unmapped := make(map[string]int)
+	for _, repo := range cca.Repos {
+		for identity, count := range repo.Activity.Unmapped {
+			unmapped[identity] += count
+		}
+	}
+	return unmapped
+}
+
 type CommitActivity struct {
-	Weekdays map[string][]int // Developer -> Weekday activity
-	Hours    map[string][]int // Developer -> Hour activity
-	Months   map[string][]int // Developer -> Month activity
-	Weeks    map[string][]int // Developer -> Week activity
+	Weekdays     map[string][]int          // Developer -> Weekday activity
+	Hours        map[string][]int          // Developer -> Hour activity
+	Months       map[string][]int          // Developer -> Month activity
+	Weeks        map[string][]int          // Developer -> Week activity
+	WeekdayHours map[string][]int          // Developer -> Joint weekday/hour activity (weekday*24 + hour)
+	Days         map[string]map[string]int // Developer -> Calendar day ("2006-01-02") -> activity
+	Unmapped     map[string]int            // "Name <email>" -> commits by authors missing from the aliases
+
+	// Lines added and deleted, recorded separately in the line modes
+	Additions *CommitActivity
+	Deletions *CommitActivity
+}
+
+// IsLinesMode reports whether mode counts changed lines rather than commits.
+func IsLinesMode(mode string) bool {
+	return mode == "lines" || mode == "added" || mode == "deleted" || mode == "net"
+}
+
+// LinesValue returns the value a commit with the given line changes counts
+// for in mode.
+func LinesValue(mode string, added, deleted int) int {
+	switch mode {
+	case "added":
+		return added
+	case "deleted":
+		return deleted
+	case "net":
+		return added - deleted
+	default:
+		return added + deleted
+	}
+}
+
+// ValueLabel describes the values counted in mode, e.g. for axis labels.
+func ValueLabel(mode string) string {
+	switch mode {
+	case "lines":
+		return "Lines of Code"
+	case "added":
+		return "Lines Added"
+	case "deleted":
+		return "Lines Deleted"
+	case "net":
+		return "Net Lines of Code"
+	default:
+		return "Commits"
+	}
 }
 
 func NewCommitActivity() *CommitActivity {
 	return &CommitActivity{
-		Weekdays: make(map[string][]int),
-		Hours:    make(map[string][]int),
-		Months:   make(map[string][]int),
-		Weeks:    make(map[string][]int),
+		Weekdays:     make(map[string][]int),
+		Hours:        make(map[string][]int),
+		Months:       make(map[string][]int),
+		Weeks:        make(map[string][]int),
+		WeekdayHours: make(map[string][]int),
+		Days:         make(map[string]map[string]int),
+		Unmapped:     make(map[string]int),
 	}
 }
 
@@ -62,6 +156,36 @@ func (ca *CommitActivity) AddActivity(developer string, weekday, hour, month, we
 		ca.Weeks[developer] = make([]int, 53)
 	}
 	ca.Weeks[developer][week] += value
+
+	if _, exists := ca.WeekdayHours[developer]; !exists {
+		ca.WeekdayHours[developer] = make([]int, 7*24)
+	}
+	ca.WeekdayHours[developer][weekday*24+hour] += value
+}
+
+// AddActivityAt records activity at the given time in all cyclic buckets and
+// on its calendar day.
+func (ca *CommitActivity) AddActivityAt(developer string, when time.Time, value int) {
+	// Month (0 = January, 11 = December), week (0 = ISO week 1, 52 = ISO
+	// week 53)
+	_, week := when.ISOWeek()
+	ca.AddActivity(developer, int(when.Weekday()), when.Hour(), int(when.Month())-1, week-1, value)
+
+	if _, exists := ca.Days[developer]; !exists {
+		ca.Days[developer] = make(map[string]int)
+	}
+	ca.Days[developer][when.Format(DayLayout)] += value
+}
+
+// AddLineChangesAt records added and deleted lines separately at the given
+// time.
+func (ca *CommitActivity) AddLineChangesAt(developer string, when time.Time, added, deleted int) {
+	if ca.Additions == nil {
+		ca.Additions = NewCommitActivity()
+		ca.Deletions = NewCommitActivity()
+	}
+	ca.Additions.AddActivityAt(developer, when, added)
+	ca.Deletions.AddActivityAt(developer, when, deleted)
 }
 
 func (ca *CommitActivity) Combine(other *CommitActivity) {
@@ -104,126 +228,78 @@ func (ca *CommitActivity) Combine(other *CommitActivity) {
 			ca.Weeks[developer][i] += value
 		}
 	}
-}
 
-func AnalyzeCommits(repoPath string, aliases DeveloperAliases, mode string) (*CommitActivity, error) {
-	activity := NewCommitActivity()
-
-	// Open the Git repository
-	repo, err := git.PlainOpen(repoPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not open repository: %w", err)
+	// Combine WeekdayHours
+	for developer, data := range other.WeekdayHours {
+		if _, exists := ca.WeekdayHours[developer]; !exists {
+			ca.WeekdayHours[developer] = make([]int, len(data))
+		}
+		for i, value := range data {
+			ca.WeekdayHours[developer][i] += value
+		}
 	}
 
-	// Get the commit history
-	ref, err := repo.Head()
-	if err != nil {
-		return nil, fmt.Errorf("could not get repository head: %w", err)
+	// Combine Days
+	for developer, data := range other.Days {
+		if _, exists := ca.Days[developer]; !exists {
+			ca.Days[developer] = make(map[string]int)
+		}
+		for day, value := range data {
+			ca.Days[developer][day] += value
+		}
 	}
 
-	commitIter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve commits: %w", err)
+	// Combine unmapped identities
+	for identity, count := range other.Unmapped {
+		ca.Unmapped[identity] += count
 	}
 
-	// Iterate through the commits
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		commitTime := c.Author.When
-		authorEmail := strings.ToLower(c.Author.Email)
-
-		// Map alias to developer name
-		developer, exists := aliases[authorEmail]
-		if !exists {
-			developer = "Unknown"
-		}
-
-		// Weekday, hour, month, and week
-		weekday := int(commitTime.Weekday())
-		hour := commitTime.Hour()
-		month := int(commitTime.Month()) - 1 // `time.Month` is 1-based
-		_, week := commitTime.ISOWeek()
-
-		if mode == "commits" {
-			// Increment commit count for the developer
-			activity.AddActivity(developer, weekday, hour, month, week, 1)
-		} else if mode == "lines" {
-			// Aggregate changed lines
-			stats, err := c.Stats()
-			if err != nil {
-				return fmt.Errorf("could not get diff stats: %w", err)
-			}
-
-			// Sum added and deleted lines
-			lineChanges := 0
-			for _, stat := range stats {
-				lineChanges += stat.Addition + stat.Deletion
-			}
-
-			// Increment line change count for the developer
-			activity.AddActivity(developer, weekday, hour, month, week, lineChanges)
+	// Combine separately recorded line changes
+	if other.Additions != nil {
+		if ca.Additions == nil {
+			ca.Additions = NewCommitActivity()
+			ca.Deletions = NewCommitActivity()
 		}
-
-		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("could not iterate through commits: %w", err)
+		ca.Additions.Combine(other.Additions)
+		ca.Deletions.Combine(other.Deletions)
 	}
-
-	return activity, nil
 }
 
-func AnalyzeCommitsInRange(repoPath string, start, end time.Time, aliases DeveloperAliases) (*CommitActivity, error) {
+// AnalyzeCommitsInRange counts the commits of a repository. If ctx is done,
+// the activity counted so far is returned together with the context's error.
+func AnalyzeCommitsInRange(ctx context.Context, repoPath string, opts AnalyzeOptions) (*CommitActivity, error) {
 	activity := NewCommitActivity()
 
-	repo, err := git.PlainOpen(repoPath)
+	ra, err := openRepoAnalysis(repoPath, opts)
 	if err != nil {
-		return nil, fmt.Errorf("could not open repository: %w", err)
+		return nil, err
 	}
+	stopCounting := ra.countCommits(ctx)
 
-	ref, err := repo.Head()
-	if err != nil {
-		return nil, fmt.Errorf("could not get repository head: %w", err)
-	}
-
-	commitIter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
-	if err != nil {
-		return nil, fmt.Errorf("could not retrieve commits: %w", err)
-	}
-
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		commitTime := c.Author.When
-		authorEmail := strings.ToLower(c.Author.Email)
-
-		// Map alias to developer name
-		developer, exists := aliases[authorEmail]
-		if !exists {
-			developer = "Unknown"
+	record := func(job commitJob) {
+		if _, ok := ra.matchingFiles(job); !ok {
+			return
 		}
-
-		// Filter commits based on date range
-		if (!start.IsZero() && commitTime.Before(start)) || (!end.IsZero() && commitTime.After(end)) {
-			return nil
+		if !ra.countIdentity(activity, job) {
+			return
 		}
+		activity.AddActivityAt(job.Developer, job.When, 1)
+	}
 
-		// Weekday and hour
-		weekday := commitTime.Weekday()
-		hour := commitTime.Hour()
-
-		// Month (0 = January, 11 = December)
-		month := commitTime.Month() - 1
-
-		// Week number (0 = first week of year)
-		_, week := commitTime.ISOWeek()
-
-		activity.AddActivity(developer, int(weekday), hour, int(month), week, 1)
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("could not iterate through commits: %w", err)
+	if opts.Paths == nil {
+		// Without path filters, commits are counted straight from the walk
+		err = ra.walk(ctx, func(job commitJob) error {
+			record(job)
+			return nil
+		})
+	} else {
+		// Commits only count if they touch a matching path, which requires
+		// their diff stats.
+		err = parallelLineStats(ctx, repoPath, opts.Jobs, ra.cache, false, ra.walker(ctx), record)
 	}
 
-	return activity, nil
+	stopCounting()
+	return ra.finish(activity, err)
 }
 
 // GetRepoName extracts the repository name from its path
@@ -240,96 +316,325 @@ func GetMultiRepoName(repoPaths []string) string {
 	return strings.Join(names, "_and_")
 }
 
-func AnalyzeLinesInRange(repoPath string, start, end time.Time, aliases DeveloperAliases) (*CommitActivity, error) {
+// AnalyzeLinesInRange counts the changed lines of a repository. If ctx is
+// done, the activity counted so far is returned together with the context's
+// error.
+func AnalyzeLinesInRange(ctx context.Context, repoPath string, opts AnalyzeOptions) (*CommitActivity, error) {
 	activity := NewCommitActivity()
 
+	ra, err := openRepoAnalysis(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	stopCounting := ra.countCommits(ctx)
+
+	err = parallelLineStats(ctx, repoPath, opts.Jobs, ra.cache, ra.classifier.excludesGenerated(), ra.walker(ctx), func(job commitJob) {
+		files, ok := ra.matchingFiles(job)
+		if !ok {
+			return
+		}
+		if !ra.countIdentity(activity, job) {
+			return
+		}
+
+		// Aggregate added and deleted lines
+		added, deleted := 0, 0
+		for _, stat := range files {
+			if ra.classifier.Excluded(stat) {
+				continue
+			}
+			added += stat.Added
+			deleted += stat.Deleted
+		}
+
+		// Increment activity data per developer
+		activity.AddActivityAt(job.Developer, job.When, LinesValue(opts.Mode, added, deleted))
+		activity.AddLineChangesAt(job.Developer, job.When, added, deleted)
+	})
+
+	stopCounting()
+	return ra.finish(activity, err)
+}
+
+// repoAnalysis holds the state shared by the analyzers of one repository.
+type repoAnalysis struct {
+	path    string
+	opts    AnalyzeOptions
+	repo    *git.Repository
+	from    []plumbing.Hash
+	mailmap *Mailmap
+	cache   *CommitCache
+
+	// Classifier honoring the repository's .gitattributes
+	classifier *FileClassifier
+
+	// Unmapped identities of commits dropped during the walk. The walk may
+	// run concurrently to recording, so they are merged in finish.
+	dropped map[string]int
+}
+
+func openRepoAnalysis(repoPath string, opts AnalyzeOptions) (*repoAnalysis, error) {
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not open repository: %w", err)
 	}
 
-	ref, err := repo.Head()
+	from, err := ResolveRefs(repo, opts.Refs, opts.AllBranches)
 	if err != nil {
-		return nil, fmt.Errorf("could not get repository head: %w", err)
+		return nil, err
 	}
 
-	commitIter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	// Tags bound the date range of each repository separately. Commit times
+	// have a resolution of one second, so the tagged commit itself is not
+	// counted after SinceTag.
+	if opts.SinceTag != "" {
+		tagTime, err := TagTime(repo, opts.SinceTag, opts.DateSource)
+		if err != nil {
+			return nil, err
+		}
+		opts.Start = tagTime.Add(time.Second)
+	}
+	if opts.UntilTag != "" {
+		if opts.End, err = TagTime(repo, opts.UntilTag, opts.DateSource); err != nil {
+			return nil, err
+		}
+	}
+
+	mailmap, err := LoadRepoMailmap(repo)
 	if err != nil {
-		return nil, fmt.Errorf("could not retrieve commits: %w", err)
+		return nil, err
 	}
+	mailmap.Merge(opts.Mailmap)
 
-	err = commitIter.ForEach(func(c *object.Commit) error {
-		commitTime := c.Author.When
-		authorEmail := strings.ToLower(c.Author.Email)
+	cache, err := LoadCommitCache(opts.CacheDir, repoPath)
+	if err != nil {
+		return nil, err
+	}
 
-		// Map alias to developer name
-		developer, exists := aliases[authorEmail]
-		if !exists {
-			developer = "Unknown"
+	classifier, err := opts.Classifier.ForRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repoAnalysis{
+		path:       repoPath,
+		opts:       opts,
+		repo:       repo,
+		from:       from,
+		mailmap:    mailmap,
+		cache:      cache,
+		classifier: classifier,
+		dropped:    make(map[string]int),
+	}, nil
+}
+
+// walk emits every commit within the date range together with its developer.
+// It stops with the context's error once ctx is done.
+func (ra *repoAnalysis) walk(ctx context.Context, emit func(job commitJob) error) error {
+	opts := ra.opts
+	return ForEachCommit(ra.repo, ra.cache, ra.from, opts.FirstParent, func(c *CommitInfo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		opts.Progress.addProcessed(ra.path, 1)
+
+		commitTime := c.When(opts.DateSource)
+
+		// Filter merge commits
+		if (opts.Merges == "exclude" && c.IsMerge()) || (opts.Merges == "only" && !c.IsMerge()) {
+			return nil
 		}
 
 		// Filter commits based on date range
-		if (!start.IsZero() && commitTime.Before(start)) || (!end.IsZero() && commitTime.After(end)) {
+		if (!opts.Start.IsZero() && commitTime.Before(opts.Start)) || (!opts.End.IsZero() && commitTime.After(opts.End)) {
 			return nil // Skip this commit
 		}
 
-		// Get the diff stats for the commit
-		stats, err := c.Stats()
-		if err != nil {
-			return fmt.Errorf("could not get diff stats: %w", err)
+		// Map alias to developer name
+		developer, identity := resolveDeveloper(c.AuthorName, c.AuthorEmail, opts.Aliases, ra.mailmap, opts.Unknown)
+
+		// Days are compared with the date the commit is bucketed by, so that
+		// they cover whole days in the chosen time zone
+		localTime := ra.localTime(developer, commitTime)
+		if opts.StartDay != "" || opts.EndDay != "" {
+			day := localTime.Format(DayLayout)
+			if (opts.StartDay != "" && day < opts.StartDay) || (opts.EndDay != "" && day > opts.EndDay) {
+				return nil
+			}
 		}
 
-		// Aggregate added and deleted lines
-		added, deleted := 0, 0
-		for _, stat := range stats {
-			added += stat.Addition
-			deleted += stat.Deletion
+		// Dropped commits only need their stats if a path filter decides
+		// whether they count as unmapped activity at all.
+		if developer == "" && opts.Paths == nil {
+			ra.dropped[identity]++
+			return nil
 		}
 
-		// Increment activity data per developer
-		weekday := int(commitTime.Weekday())
-		hour := commitTime.Hour()
-		month := int(commitTime.Month()) - 1 // Month() is 1-based, adjust to 0-based
-		_, week := commitTime.ISOWeek()
-
-		// Add activity
-		if weekday >= 0 && weekday < 7 && hour >= 0 && hour < 24 && month >= 0 && month < 12 && week >= 0 && week < 53 {
-			activity.AddActivity(developer, weekday, hour, month, week, added+deleted)
+		return emit(commitJob{Info: c, Developer: developer, Identity: identity, When: localTime})
+	})
+}
+
+// localTime converts a commit time to the zone configured for developer. The
+// hour and weekday buckets are taken from the result.
+func (ra *repoAnalysis) localTime(developer string, t time.Time) time.Time {
+	if location, ok := ra.opts.Zones[developer]; ok {
+		return t.In(location)
+	}
+	if ra.opts.Location != nil {
+		return t.In(ra.opts.Location)
+	}
+	return t
+}
+
+// walker returns walk bound to ctx, as expected by parallelLineStats.
+func (ra *repoAnalysis) walker(ctx context.Context) func(emit func(job commitJob) error) error {
+	return func(emit func(job commitJob) error) error {
+		return ra.walk(ctx, emit)
+	}
+}
+
+// matchingFiles returns the diff stats of the files that pass the path
+// filter, and whether the commit counts at all.
+func (ra *repoAnalysis) matchingFiles(job commitJob) ([]FileStat, bool) {
+	if ra.opts.Paths == nil {
+		return job.Info.Files, true
+	}
+
+	var files []FileStat
+	for _, stat := range job.Info.Files {
+		if ra.opts.Paths.MatchStat(stat) {
+			files = append(files, stat)
 		}
+	}
+	return files, len(files) > 0
+}
 
-		return nil
-	})
+// countIdentity records the identity of a counted commit if it is unmapped
+// and reports whether the commit should be recorded for its developer.
+func (ra *repoAnalysis) countIdentity(activity *CommitActivity, job commitJob) bool {
+	if job.Identity != "" {
+		activity.Unmapped[job.Identity]++
+	}
+	return job.Developer != ""
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("could not iterate through commits: %w", err)
+// finish merges the identities of dropped commits and saves the cache, also
+// after an interrupted walk so that its work is not lost. If the walk was
+// interrupted by its context, the partial activity is returned with the
+// walk's error.
+func (ra *repoAnalysis) finish(activity *CommitActivity, walkErr error) (*CommitActivity, error) {
+	for identity, count := range ra.dropped {
+		activity.Unmapped[identity] += count
 	}
 
+	if err := ra.cache.Save(); err != nil {
+		return nil, err
+	}
+
+	if errors.Is(walkErr, context.Canceled) || errors.Is(walkErr, context.DeadlineExceeded) {
+		return activity, walkErr
+	}
+	if walkErr != nil {
+		return nil, fmt.Errorf("could not iterate through commits: %w", walkErr)
+	}
 	return activity, nil
 }
 
-func AnalyzeRepositories(repoPaths []string, start, end time.Time, mode string, aliases DeveloperAliases) (string, *CombinedCommitActivity) {
-	fmt.Printf("Analyzing %d repositories in '%s' mode...\n", len(repoPaths), mode)
+// AnalyzeRepositories analyzes all repositories and combines their activity.
+// If a repository fails, no further repositories are started and an
+// *AnalysisError is returned. With opts.KeepGoing, failed repositories are
+// skipped instead: the activity of all others is returned together with an
+// *AnalysisError listing the failures.
+//
+// Once ctx is done, no further repositories are started and the running ones
+// stop. The activity collected so far is returned marked as partial, together
+// with the context's error.
+func AnalyzeRepositories(ctx context.Context, repoPaths []string, opts AnalyzeOptions) (string, *CombinedCommitActivity, error) {
+	mode := opts.Mode
+	if mode != "commits" && !IsLinesMode(mode) {
+		return "", nil, fmt.Errorf("unsupported mode '%s'", mode)
+	}
+
 	combinedActivity := &CombinedCommitActivity{}
+	opts.Progress.setRepos(len(repoPaths))
+
+	// Split the workers between repositories and the diff stats within each
+	// repository, so that at most opts.Jobs commits are processed at a time.
+	jobs := effectiveJobs(opts.Jobs)
+	repoWorkers := max(min(jobs, len(repoPaths)), 1)
+	opts.Jobs = max(jobs/repoWorkers, 1)
+
+	// Results are stored by index so the combined activity does not depend on
+	// which repository finishes first.
+	activities := make([]*CommitActivity, len(repoPaths))
+	failures := make([]*RepoError, len(repoPaths))
+	indices := make(chan int)
+
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < repoWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				if ctx.Err() != nil {
+					continue
+				}
+				repoPath := repoPaths[index]
+				if opts.Progress != nil && !opts.Progress.showsStatus() {
+					slog.Info("Analyzing repository", "repository", repoPath)
+				}
+				opts.Progress.startRepo(repoPath)
+
+				var activity *CommitActivity
+				var err error
+
+				// Choose analysis method based on mode
+				if mode == "commits" {
+					activity, err = AnalyzeCommitsInRange(ctx, repoPath, opts)
+				} else {
+					activity, err = AnalyzeLinesInRange(ctx, repoPath, opts)
+				}
+
+				opts.Progress.finishRepo(repoPath)
+
+				if err != nil && ctx.Err() == nil {
+					failures[index] = &RepoError{RepoPath: repoPath, Err: err}
+					failed.Store(true)
+					continue
+				}
+
+				activities[index] = activity
+			}
+		}()
+	}
 
-	for _, repoPath := range repoPaths {
-		fmt.Printf("Analyzing repository: %s\n", repoPath)
-		repoName := GetRepoName(repoPath)
+dispatch:
+	for index := range repoPaths {
+		if failed.Load() && !opts.KeepGoing {
+			break
+		}
+		select {
+		case indices <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indices)
+	wg.Wait()
 
-		var activity *CommitActivity
-		var err error
+	// Repositories that were interrupted or never started are missing
+	combinedActivity.Partial = ctx.Err() != nil
 
-		// Choose analysis method based on mode
-		if mode == "commits" {
-			activity, err = AnalyzeCommitsInRange(repoPath, start, end, aliases)
-		} else if mode == "lines" {
-			activity, err = AnalyzeLinesInRange(repoPath, start, end, aliases)
+	analysisErr := &AnalysisError{}
+	for index, repoPath := range repoPaths {
+		if failures[index] != nil {
+			analysisErr.Failed = append(analysisErr.Failed, failures[index])
+			continue
 		}
-
-		if err != nil {
-			log.Fatalf("Error analyzing %s for %s: %v", mode, repoPath, err)
+		if activities[index] != nil {
+			combinedActivity.Add(GetRepoName(repoPath), activities[index])
 		}
-
-		combinedActivity.Add(repoName, activity)
 	}
 
 	outputPrefix := GetMultiRepoName(repoPaths)
@@ -337,5 +642,12 @@ func AnalyzeRepositories(repoPaths []string, start, end time.Time, mode string,
 		outputPrefix = "combined"
 	}
 
-	return outputPrefix, combinedActivity
+	var err error
+	if len(analysisErr.Failed) > 0 {
+		err = analysisErr
+	}
+	if ctx.Err() != nil {
+		err = errors.Join(fmt.Errorf("analysis interrupted: %w", ctx.Err()), err)
+	}
+	return outputPrefix, combinedActivity, err
 }
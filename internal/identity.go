@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveDeveloper maps a commit author to a developer name. Identities are
+// canonicalized through the mailmap first, but an alias for the original
+// email in the people file takes precedence over the mailmap.
+//
+// Authors missing from the aliases are handled according to policy:
+// "author-name" (the default) uses their canonical name, "email" their
+// canonical email, "unknown" pools them as "Unknown" and "drop" returns an
+// empty developer so the commit is skipped. For these authors the canonical
+// "Name <email>" identity is returned as unmapped.
+func resolveDeveloper(authorName, authorEmail string, aliases DeveloperAliases, mailmap *Mailmap, policy string) (developer, unmapped string) {
+	if developer, exists := aliases[strings.ToLower(authorEmail)]; exists {
+		return developer, ""
+	}
+
+	name, email := mailmap.Resolve(authorName, authorEmail)
+	email = strings.ToLower(email)
+	if developer, exists := aliases[email]; exists {
+		return developer, ""
+	}
+
+	unmapped = fmt.Sprintf("%s <%s>", name, email)
+
+	switch policy {
+	case "email":
+		return email, unmapped
+	case "unknown":
+		return "Unknown", unmapped
+	case "drop":
+		return "", unmapped
+	default:
+		if name == "" {
+			return email, unmapped
+		}
+		return name, unmapped
+	}
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveDeveloper(t *testing.T) {
+	aliases := DeveloperAliases{
+		"alice@example.com":     "Alice",
+		"bob@example.com":       "Bob",
+		"carol@old.example.com": "Carol (old)",
+	}
+	mailmap, err := ParseMailmap(strings.NewReader(`<bob@example.com> <bob@old.example.com>
+Carol New <carol@example.com> <carol@old.example.com>
+Dan Mapped <dan@example.com> <dan@old.example.com>
+`))
+	if err != nil {
+		t.Fatalf("ParseMailmap failed: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorName    string
+		authorEmail   string
+		policy        string
+		wantDeveloper string
+		wantUnmapped  string
+	}{
+		{"alias", "A", "alice@example.com", "", "Alice", ""},
+		{"alias ignores case", "A", "ALICE@Example.com", "", "Alice", ""},
+		{"alias of mailmap email", "Bob", "bob@old.example.com", "", "Bob", ""},
+		{"alias of original email wins over mailmap", "Carol", "carol@old.example.com", "", "Carol (old)", ""},
+		{"unmapped uses canonical name", "Dan", "Dan@Old.example.com", "", "Dan Mapped", "Dan Mapped <dan@example.com>"},
+		{"author-name policy", "Erin", "erin@example.com", "author-name", "Erin", "Erin <erin@example.com>"},
+		{"author-name without name", "", "Erin@example.com", "author-name", "erin@example.com", " <erin@example.com>"},
+		{"email policy", "Dan", "dan@old.example.com", "email", "dan@example.com", "Dan Mapped <dan@example.com>"},
+		{"unknown policy", "Erin", "erin@example.com", "unknown", "Unknown", "Erin <erin@example.com>"},
+		{"drop policy", "Erin", "erin@example.com", "drop", "", "Erin <erin@example.com>"},
+		{"drop policy keeps aliases", "A", "alice@example.com", "drop", "Alice", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			developer, unmapped := resolveDeveloper(test.authorName, test.authorEmail, aliases, mailmap, test.policy)
+			if developer != test.wantDeveloper || unmapped != test.wantUnmapped {
+				t.Errorf("resolveDeveloper(%q, %q) = %q, %q, want %q, %q",
+					test.authorName, test.authorEmail, developer, unmapped, test.wantDeveloper, test.wantUnmapped)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Mailmap canonicalizes author identities following git's .mailmap format.
+type Mailmap struct {
+	entries map[mailmapKey]mailmapEntry
+}
+
+type mailmapKey struct {
+	email string // Lowercased commit email
+	name  string // Lowercased commit name, empty to match any name
+}
+
+type mailmapEntry struct {
+	name  string // Proper name, empty to keep the commit name
+	email string // Proper email, empty to keep the commit email
+}
+
+func NewMailmap() *Mailmap {
+	return &Mailmap{entries: make(map[mailmapKey]mailmapEntry)}
+}
+
+// ParseMailmap reads mailmap entries in any of the forms git supports:
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func ParseMailmap(r io.Reader) (*Mailmap, error) {
+	m := NewMailmap()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		m.addLine(line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// LoadMailmap parses the mailmap file at the given path.
+func LoadMailmap(filename string) (*Mailmap, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseMailmap(file)
+}
+
+// LoadRepoMailmap reads the .mailmap of a repository from its worktree, or
+// from the HEAD tree for bare repositories. A missing file yields an empty
+// mailmap.
+func LoadRepoMailmap(repo *git.Repository) (*Mailmap, error) {
+	if wt, err := repo.Worktree(); err == nil {
+		file, err := wt.Filesystem.Open(".mailmap")
+		if errors.Is(err, os.ErrNotExist) {
+			return NewMailmap(), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not open .mailmap: %w", err)
+		}
+		defer file.Close()
+		return ParseMailmap(file)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return NewMailmap(), nil
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("could not read HEAD commit: %w", err)
+	}
+	file, err := commit.File(".mailmap")
+	if errors.Is(err, object.ErrFileNotFound) {
+		return NewMailmap(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open .mailmap: %w", err)
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("could not read .mailmap: %w", err)
+	}
+	return ParseMailmap(strings.NewReader(contents))
+}
+
+// Merge adds all entries of other to m, overriding entries for the same
+// identity.
+func (m *Mailmap) Merge(other *Mailmap) {
+	if other == nil {
+		return
+	}
+	for key, entry := range other.entries {
+		m.entries[key] = entry
+	}
+}
+
+// Resolve returns the canonical name and email for a commit identity. Entries
+// that match both name and email take precedence over email-only entries.
+func (m *Mailmap) Resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+
+	entry, ok := m.entries[mailmapKey{email: strings.ToLower(email), name: strings.ToLower(name)}]
+	if !ok {
+		entry, ok = m.entries[mailmapKey{email: strings.ToLower(email)}]
+	}
+	if !ok {
+		return name, email
+	}
+
+	if entry.name != "" {
+		name = entry.name
+	}
+	if entry.email != "" {
+		email = entry.email
+	}
+	return name, email
+}
+
+func (m *Mailmap) addLine(line string) {
+	var names, emails []string
+
+	rest := line
+	for {
+		open := strings.Index(rest, "<")
+		if open < 0 {
+			break
+		}
+		closing := strings.Index(rest[open:], ">")
+		if closing < 0 {
+			break
+		}
+		names = append(names, strings.TrimSpace(rest[:open]))
+		emails = append(emails, strings.TrimSpace(rest[open+1:open+closing]))
+		rest = rest[open+closing+1:]
+	}
+
+	switch len(emails) {
+	case 1:
+		// Proper Name <commit@email>
+		if names[0] == "" {
+			return
+		}
+		m.entries[mailmapKey{email: strings.ToLower(emails[0])}] = mailmapEntry{name: names[0]}
+	case 2:
+		// [Proper Name] <proper@email> [Commit Name] <commit@email>
+		key := mailmapKey{email: strings.ToLower(emails[1]), name: strings.ToLower(names[1])}
+		m.entries[key] = mailmapEntry{name: names[0], email: emails[0]}
+	}
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMailmap = `# Comments and blank lines are ignored
+
+Alice Example <alice@example.com>
+<bob@example.com> <bob@old.example.com>
+Carol Example <carol@example.com> <carol@old.example.com>
+Dave Example <dave@example.com> dave <shared@example.com>
+Eve Example <eve@example.com> Eve <shared@example.com> # trailing comment
+<ignored@example.com>
+Frank <frank@example.com>
+Franky <franky@example.com> F <frank@example.com>
+`
+
+func TestParseMailmap(t *testing.T) {
+	m, err := ParseMailmap(strings.NewReader(testMailmap))
+	if err != nil {
+		t.Fatalf("ParseMailmap failed: %v", err)
+	}
+
+	tests := []struct {
+		name, email         string
+		wantName, wantEmail string
+	}{
+		// Proper Name <commit@email> keeps the commit email
+		{"alice", "alice@example.com", "Alice Example", "alice@example.com"},
+		{"alice", "Alice@Example.com", "Alice Example", "Alice@Example.com"},
+		// <proper@email> <commit@email> keeps the commit name
+		{"Bob", "bob@old.example.com", "Bob", "bob@example.com"},
+		// Proper Name <proper@email> <commit@email>
+		{"C.", "carol@old.example.com", "Carol Example", "carol@example.com"},
+		// Proper Name <proper@email> Commit Name <commit@email> only matches
+		// the commit name, case-insensitively
+		{"Dave", "shared@example.com", "Dave Example", "dave@example.com"},
+		{"EVE", "shared@example.com", "Eve Example", "eve@example.com"},
+		{"Mallory", "shared@example.com", "Mallory", "shared@example.com"},
+		// Name and email entries take precedence over email-only entries
+		{"F", "frank@example.com", "Franky", "franky@example.com"},
+		{"Frank F.", "frank@example.com", "Frank", "frank@example.com"},
+		// Entries without a proper name or email are ignored
+		{"Ivan", "ignored@example.com", "Ivan", "ignored@example.com"},
+		{"Nobody", "nobody@example.com", "Nobody", "nobody@example.com"},
+	}
+
+	for _, test := range tests {
+		name, email := m.Resolve(test.name, test.email)
+		if name != test.wantName || email != test.wantEmail {
+			t.Errorf("Resolve(%q, %q) = %q, %q, want %q, %q", test.name, test.email, name, email, test.wantName, test.wantEmail)
+		}
+	}
+}
+
+func TestMailmapMerge(t *testing.T) {
+	m, err := ParseMailmap(strings.NewReader("Alice <alice@example.com>\nBob <bob@example.com>\n"))
+	if err != nil {
+		t.Fatalf("ParseMailmap failed: %v", err)
+	}
+	other, err := ParseMailmap(strings.NewReader("Alice Example <alice@example.com>\n"))
+	if err != nil {
+		t.Fatalf("ParseMailmap failed: %v", err)
+	}
+	m.Merge(other)
+	m.Merge(nil)
+
+	if name, _ := m.Resolve("a", "alice@example.com"); name != "Alice Example" {
+		t.Errorf("merged entry resolves to %q, want %q", name, "Alice Example")
+	}
+	if name, _ := m.Resolve("b", "bob@example.com"); name != "Bob" {
+		t.Errorf("kept entry resolves to %q, want %q", name, "Bob")
+	}
+
+	var empty *Mailmap
+	if name, email := empty.Resolve("a", "a@example.com"); name != "a" || email != "a@example.com" {
+		t.Errorf("nil mailmap resolves to %q, %q", name, email)
+	}
+}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+// errStopped is returned to the commit walk once a worker has failed.
+var errStopped = errors.New("line stats workers stopped")
+
+// commitJob is a commit selected by the walk, with its resolved developer
+// and, for authors missing from the aliases, their identity.
+type commitJob struct {
+	Info      *CommitInfo
+	Developer string
+	Identity  string
+	When      time.Time
+}
+
+// effectiveJobs returns the number of workers to use for n, defaulting to
+// the number of CPUs.
+func effectiveJobs(n int) int {
+	if n <= 0 {
+		return runtime.NumCPU()
+	}
+	return n
+}
+
+// parallelLineStats computes diff stats for the commits emitted by walk on
+// the given number of workers, unless they are already cached, and passes the
+// jobs to record, which is always called from a single goroutine. With
+// headers, the changed files are also checked for generated headers. Each
+// worker opens its own handle on the repository because go-git repositories
+// are not safe for concurrent use. Once ctx is done, no further stats are
+// computed.
+func parallelLineStats(
+	ctx context.Context, repoPath string, workers int, cache *CommitCache, headers bool,
+	walk func(emit func(job commitJob) error) error,
+	record func(job commitJob),
+) error {
+	jobs := make(chan commitJob)
+	results := make(chan commitJob)
+	stop := make(chan struct{})
+
+	var (
+		workerErr error
+		stopOnce  sync.Once
+	)
+	fail := func(err error) {
+		stopOnce.Do(func() {
+			workerErr = err
+			close(stop)
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < effectiveJobs(workers); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// The repository is only opened once stats are actually needed
+			var repo *git.Repository
+
+			for job := range jobs {
+				select {
+				case <-stop:
+					continue // Drain remaining jobs
+				case <-ctx.Done():
+					continue
+				default:
+				}
+
+				if !job.Info.HasStats || headers && !job.Info.HasHeaders {
+					if repo == nil {
+						var err error
+						if repo, err = git.PlainOpen(repoPath); err != nil {
+							fail(fmt.Errorf("could not open repository: %w", err))
+							continue
+						}
+					}
+
+					commit, err := repo.CommitObject(job.Info.Hash)
+					if err != nil {
+						fail(fmt.Errorf("could not retrieve commit %s: %w", job.Info.Hash, err))
+						continue
+					}
+
+					// Stats cached without headers are only checked for them
+					files := slices.Clone(job.Info.Files)
+					if !job.Info.HasStats {
+						if files, err = newFileStats(commit); err != nil {
+							fail(err)
+							continue
+						}
+					}
+					checked := job.Info.HasHeaders
+					if headers && !checked {
+						if err := checkGeneratedHeaders(commit, files); err != nil {
+							fail(err)
+							continue
+						}
+						checked = true
+					}
+					cache.SetStats(job.Info, files, checked)
+				}
+
+				results <- job
+			}
+		}()
+	}
+
+	recorded := make(chan struct{})
+	go func() {
+		defer close(recorded)
+		for job := range results {
+			record(job)
+		}
+	}()
+
+	walkErr := walk(func(job commitJob) error {
+		select {
+		case jobs <- job:
+			return nil
+		case <-stop:
+			return errStopped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	close(jobs)
+	wg.Wait()
+	close(results)
+	<-recorded
+
+	if workerErr != nil {
+		return workerErr
+	}
+	return walkErr
+}
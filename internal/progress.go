@@ -0,0 +1,292 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+// Intervals between progress updates on a terminal and in the log
+const (
+	terminalProgressInterval = 200 * time.Millisecond
+	logProgressInterval      = 10 * time.Second
+)
+
+// ProgressTracker counts the commits walked per repository. A nil tracker
+// ignores all updates. It is safe for concurrent use.
+type ProgressTracker struct {
+	mu      sync.Mutex
+	started time.Time
+	repos   map[string]*repoProgress
+	total   int  // Number of repositories
+	status  bool // Whether a status line shows the current repositories
+}
+
+type repoProgress struct {
+	processed int
+	total     int // Number of reachable commits; -1 until counted
+	done      bool
+}
+
+// ProgressSnapshot is the state of a ProgressTracker at one point in time.
+type ProgressSnapshot struct {
+	Elapsed    time.Duration
+	Repos      int      // Number of repositories to analyze
+	Started    int      // Number of repositories analyzed or being analyzed
+	Finished   int      // Number of repositories analyzed
+	Current    []string // Repositories being analyzed
+	Counted    int      // Number of started repositories whose commits are counted
+	Processed  int      // Commits walked
+	Total      int      // Reachable commits, extrapolated for repositories not yet started
+	TotalKnown bool     // Whether all repositories have been counted
+}
+
+// NewProgressTracker returns a tracker whose rate is measured from now.
+func NewProgressTracker() *ProgressTracker {
+	return &ProgressTracker{started: time.Now(), repos: make(map[string]*repoProgress)}
+}
+
+// setRepos sets the number of repositories to analyze.
+func (pt *ProgressTracker) setRepos(n int) {
+	if pt == nil {
+		return
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.total = n
+}
+
+func (pt *ProgressTracker) startRepo(repoPath string) {
+	if pt == nil {
+		return
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.repo(repoPath)
+}
+
+func (pt *ProgressTracker) setTotal(repoPath string, commits int) {
+	if pt == nil {
+		return
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if progress := pt.repo(repoPath); !progress.done {
+		progress.total = commits
+	}
+}
+
+func (pt *ProgressTracker) addProcessed(repoPath string, commits int) {
+	if pt == nil {
+		return
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.repo(repoPath).processed += commits
+}
+
+func (pt *ProgressTracker) finishRepo(repoPath string) {
+	if pt == nil {
+		return
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	progress := pt.repo(repoPath)
+	progress.done = true
+	// Finished repositories no longer need an estimate
+	progress.total = progress.processed
+}
+
+// repo returns the progress of a repository, which is added on first use.
+// pt.mu must be held.
+func (pt *ProgressTracker) repo(repoPath string) *repoProgress {
+	progress, ok := pt.repos[repoPath]
+	if !ok {
+		progress = &repoProgress{total: -1}
+		pt.repos[repoPath] = progress
+	}
+	return progress
+}
+
+// showsStatus reports whether the current repositories are shown on a status
+// line, which makes announcing them separately redundant.
+func (pt *ProgressTracker) showsStatus() bool {
+	if pt == nil {
+		return false
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.status
+}
+
+// Snapshot returns the current progress.
+func (pt *ProgressTracker) Snapshot() ProgressSnapshot {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	snapshot := ProgressSnapshot{Elapsed: time.Since(pt.started), Repos: pt.total, Started: len(pt.repos)}
+	for repoPath, progress := range pt.repos {
+		snapshot.Processed += progress.processed
+		if progress.done {
+			snapshot.Finished++
+		} else {
+			snapshot.Current = append(snapshot.Current, GetRepoName(repoPath))
+		}
+		if progress.total >= 0 {
+			snapshot.Counted++
+			snapshot.Total += max(progress.total, progress.processed)
+		}
+	}
+	sort.Strings(snapshot.Current)
+
+	// Repositories not yet started are assumed to be of average size
+	snapshot.TotalKnown = snapshot.Counted == snapshot.Repos
+	if snapshot.Estimated() && snapshot.Repos > snapshot.Started {
+		snapshot.Total += snapshot.Total / snapshot.Counted * (snapshot.Repos - snapshot.Started)
+	}
+	return snapshot
+}
+
+// Rate returns the commits walked per second.
+func (ps ProgressSnapshot) Rate() float64 {
+	if ps.Elapsed <= 0 {
+		return 0
+	}
+	return float64(ps.Processed) / ps.Elapsed.Seconds()
+}
+
+// Estimated reports whether Total is at least an estimate, which is the case
+// once the commits of all started repositories have been counted.
+func (ps ProgressSnapshot) Estimated() bool {
+	return ps.Counted > 0 && ps.Counted == ps.Started
+}
+
+// ETA estimates the remaining time from the current rate and Total.
+func (ps ProgressSnapshot) ETA() (time.Duration, bool) {
+	rate := ps.Rate()
+	if !ps.Estimated() || rate <= 0 {
+		return 0, false
+	}
+	remaining := float64(ps.Total - ps.Processed)
+	return time.Duration(remaining / rate * float64(time.Second)).Round(time.Second), true
+}
+
+func (ps ProgressSnapshot) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%d/%d repos]", ps.Finished, ps.Repos)
+	if len(ps.Current) > 0 {
+		fmt.Fprintf(&b, " %s:", strings.Join(ps.Current, ", "))
+	}
+	fmt.Fprintf(&b, " %d", ps.Processed)
+	switch {
+	case ps.TotalKnown:
+		fmt.Fprintf(&b, "/%d", ps.Total)
+	case ps.Estimated():
+		fmt.Fprintf(&b, "/~%d", ps.Total)
+	}
+	fmt.Fprintf(&b, " commits, %.0f commits/s", ps.Rate())
+	if eta, ok := ps.ETA(); ok {
+		fmt.Fprintf(&b, ", ETA %s", eta)
+	}
+	return b.String()
+}
+
+// ShowProgress displays the progress of pt until the returned function is
+// called. On a terminal, a status line on w is updated continuously;
+// otherwise progress is logged periodically with slog.
+func ShowProgress(pt *ProgressTracker, w io.Writer) (stop func()) {
+	file, _ := w.(*os.File)
+	terminal := isTerminal(file)
+
+	interval := logProgressInterval
+	if terminal {
+		interval = terminalProgressInterval
+		pt.mu.Lock()
+		pt.status = true
+		pt.mu.Unlock()
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				if terminal {
+					fmt.Fprint(w, "\r\x1b[K") // Clear the status line
+				}
+				return
+			case <-ticker.C:
+				snapshot := pt.Snapshot()
+				if terminal {
+					fmt.Fprintf(w, "\r\x1b[K%s", snapshot)
+					continue
+				}
+				args := []any{"repos_done", snapshot.Finished, "repos", snapshot.Repos, "current", strings.Join(snapshot.Current, ","),
+					"commits", snapshot.Processed, "commits_per_sec", int(snapshot.Rate())}
+				if snapshot.Estimated() {
+					args = append(args, "total_commits", snapshot.Total, "total_estimated", !snapshot.TotalKnown)
+				}
+				if eta, ok := snapshot.ETA(); ok {
+					args = append(args, "eta", eta.String())
+				}
+				slog.Info("Progress", args...)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// countCommits counts the commits reachable from the analyzed refs in the
+// background for the progress estimate. The returned function stops counting
+// and waits for it, so that the cache it shares with the analysis can be
+// saved safely. It uses its own repository handle so that it can run
+// alongside the analysis.
+func (ra *repoAnalysis) countCommits(ctx context.Context) (stop func()) {
+	if ra.opts.Progress == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		repo, err := git.PlainOpen(ra.path)
+		if err != nil {
+			return
+		}
+
+		count := 0
+		err = ForEachCommit(repo, ra.cache, ra.from, ra.opts.FirstParent, func(*CommitInfo) error {
+			count++
+			return ctx.Err()
+		})
+		if err == nil {
+			slog.Debug("Counted commits", "repository", ra.path, "commits", count)
+			ra.opts.Progress.setTotal(ra.path, count)
+		}
+	}()
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
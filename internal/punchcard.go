@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// WeekdayHourLabels returns labels for the joint weekday/hour buckets.
+func WeekdayHourLabels() []string {
+	labels := make([]string, 0, 7*24)
+	for _, weekday := range WeekdayLabels() {
+		for _, hour := range HourLabels() {
+			labels = append(labels, weekday+" "+hour)
+		}
+	}
+	return labels
+}
+
+// generatePunchCards renders one weekday × hour punch card per stacking
+// group: per developer, per repository, or a single one in flat mode.
+func generatePunchCards(renderer ChartRenderer, combinedActivity *CombinedCommitActivity, mode, stacking, outputPrefix, format string) error {
+	groups := make(map[string][]int)
+	addTo := func(group string, values []int) {
+		if groups[group] == nil {
+			groups[group] = make([]int, 7*24)
+		}
+		for i, value := range values {
+			groups[group][i] += value
+		}
+	}
+
+	for _, repoActivity := range combinedActivity.Repos {
+		for developer, values := range repoActivity.Activity.WeekdayHours {
+			switch stacking {
+			case "dev", "developer":
+				addTo(developer, values)
+			case "repo", "repository":
+				addTo(repoActivity.RepoName, values)
+			default:
+				addTo("All", values)
+			}
+		}
+	}
+
+	unit := strings.ToLower(ValueLabel(mode))
+
+	for j, group := range sortedKeys(groups) {
+		title := fmt.Sprintf("Activity by Weekday and Hour (%s)", unit)
+		fileName := fmt.Sprintf("%s_punchcard.%s", outputPrefix, format)
+		if stacking != "" {
+			title = fmt.Sprintf("Activity by Weekday and Hour: %s (%s)", group, unit)
+			fileName = fmt.Sprintf("%s_punchcard_%s_%s.%s", outputPrefix, stacking, sanitizeFileName(group), format)
+		}
+
+		err := renderer.PunchCard(groups[group], decorateTitle(combinedActivity, title), fileName, colorPalette[j%len(colorPalette)])
+		if err != nil {
+			return fmt.Errorf("error creating punch card for %s: %w", group, err)
+		}
+	}
+
+	return nil
+}
+
+// CreatePunchCard creates a GitHub-style punch card from joint weekday/hour
+// data (indexed by weekday*24 + hour). Circle area is proportional to the
+// absolute activity in each slot.
+func CreatePunchCard(data []int, title, filename string, c color.Color) error {
+	p, err := newPunchCard(data, title, c)
+	if err != nil {
+		return err
+	}
+	return p.Save(chartWidth, chartHeight, filename)
+}
+
+func newPunchCard(data []int, title string, c color.Color) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "Hours"
+
+	maxValue := 0
+	for _, value := range data {
+		maxValue = max(maxValue, abs(value))
+	}
+
+	var points plotter.XYs
+	var values []int
+	for i, value := range data {
+		if value == 0 {
+			continue
+		}
+		weekday, hour := i/24, i%24
+		// Sunday on top, as in the weekday charts
+		points = append(points, plotter.XY{X: float64(hour), Y: float64(6 - weekday)})
+		values = append(values, abs(value))
+	}
+
+	p.Add(plotter.NewGrid())
+
+	maxRadius := vg.Points(14)
+	if len(points) > 0 {
+		scatter, err := plotter.NewScatter(points)
+		if err != nil {
+			return nil, fmt.Errorf("could not create punch card: %w", err)
+		}
+		scatter.GlyphStyleFunc = func(i int) draw.GlyphStyle {
+			return draw.GlyphStyle{
+				Color:  c,
+				Shape:  draw.CircleGlyph{},
+				Radius: maxRadius * vg.Length(math.Sqrt(float64(values[i])/float64(maxValue))),
+			}
+		}
+		p.Add(scatter)
+	}
+
+	p.NominalX(HourLabels()...)
+	weekdays := WeekdayLabels()
+	yTicks := make([]plot.Tick, len(weekdays))
+	for i, weekday := range weekdays {
+		yTicks[i] = plot.Tick{Value: float64(6 - i), Label: weekday}
+	}
+	p.Y.Tick.Marker = plot.ConstantTicks(yTicks)
+	p.X.Min, p.X.Max = -0.5, 23.5
+	p.Y.Min, p.Y.Max = -0.5, 6.5
+
+	return p, nil
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// sanitizeFileName replaces characters that are unsafe in file names.
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
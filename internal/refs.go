@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// ResolveRefs resolves branch names, tags, revisions and glob patterns (e.g.
+// "release/*") to the commit hashes they point at. With allBranches set, every
+// local and remote-tracking branch is included as well. If nothing is
+// requested, HEAD is used.
+func ResolveRefs(repo *git.Repository, refs []string, allBranches bool) ([]plumbing.Hash, error) {
+	seen := make(map[plumbing.Hash]bool)
+	var hashes []plumbing.Hash
+
+	add := func(hash plumbing.Hash) {
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+
+	if len(refs) == 0 && !allBranches {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("could not get repository head: %w", err)
+		}
+		return []plumbing.Hash{head.Hash()}, nil
+	}
+
+	if allBranches {
+		names, err := matchReferences(repo, func(name plumbing.ReferenceName) bool {
+			return name.IsBranch() || name.IsRemote()
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			hash, err := repo.ResolveRevision(plumbing.Revision(name))
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve branch %s: %w", name.Short(), err)
+			}
+			add(*hash)
+		}
+	}
+
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+
+		if !isGlobPattern(ref) {
+			hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve ref %s: %w", ref, err)
+			}
+			add(*hash)
+			continue
+		}
+
+		if _, err := path.Match(ref, ""); err != nil {
+			return nil, fmt.Errorf("invalid ref pattern %s: %w", ref, err)
+		}
+
+		names, err := matchReferences(repo, func(name plumbing.ReferenceName) bool {
+			return matchRefPattern(ref, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			// Patterns are shared across repositories, so a miss in one of
+			// them is expected and not an error on its own.
+			slog.Warn("Ref pattern matched no references", "pattern", ref)
+			continue
+		}
+		for _, name := range names {
+			hash, err := repo.ResolveRevision(plumbing.Revision(name))
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve ref %s: %w", name.Short(), err)
+			}
+			add(*hash)
+		}
+	}
+
+	if len(hashes) == 0 {
+		return nil, errors.New("none of the requested refs exist in this repository")
+	}
+
+	return hashes, nil
+}
+
+// ForEachCommit walks the union of the histories reachable from the given
+// hashes and calls fn once per commit, deduplicated by hash. Commit metadata
+// is taken from the cache where possible, so already known history is walked
+// without touching the object store. With firstParent, only the first parent
+// of merge commits is followed.
+func ForEachCommit(
+	repo *git.Repository, cache *CommitCache, from []plumbing.Hash, firstParent bool,
+	fn func(info *CommitInfo) error,
+) error {
+	seen := make(map[plumbing.Hash]bool)
+	stack := make([]plumbing.Hash, 0, len(from))
+	for i := len(from) - 1; i >= 0; i-- {
+		stack = append(stack, from[i])
+	}
+
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		info, err := cache.CommitInfo(repo, hash)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(info); err != nil {
+			if errors.Is(err, storer.ErrStop) {
+				return nil
+			}
+			return err
+		}
+
+		parents := info.Parents
+		if firstParent && len(parents) > 1 {
+			parents = parents[:1]
+		}
+
+		// Push parents in reverse so the first parent is visited first
+		for i := len(parents) - 1; i >= 0; i-- {
+			if !seen[parents[i]] {
+				stack = append(stack, parents[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// TagTime returns the date of the commit a tag points to, by author or
+// committer according to dateSource.
+func TagTime(repo *git.Repository, tag, dateSource string) (time.Time, error) {
+	ref, err := repo.Tag(tag)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not find tag '%s': %w", tag, err)
+	}
+
+	// Annotated tags point to a tag object rather than a commit
+	hash := ref.Hash()
+	if tagObject, err := repo.TagObject(hash); err == nil {
+		commit, err := tagObject.Commit()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("tag '%s' does not point to a commit: %w", tag, err)
+		}
+		hash = commit.Hash
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("could not retrieve commit of tag '%s': %w", tag, err)
+	}
+	return newCommitInfo(commit).When(dateSource), nil
+}
+
+// matchReferences returns the names of all non-symbolic references accepted
+// by match, sorted for a deterministic walk order.
+func matchReferences(repo *git.Repository, match func(name plumbing.ReferenceName) bool) ([]plumbing.ReferenceName, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("could not list references: %w", err)
+	}
+
+	var names []plumbing.ReferenceName
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || ref.Name() == plumbing.HEAD {
+			return nil
+		}
+		if match(ref.Name()) {
+			names = append(names, ref.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list references: %w", err)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names, nil
+}
+
+// matchRefPattern matches a glob against the full reference name as well as
+// its short forms, so "release/*", "origin/*" and "refs/tags/v1.*" all work.
+func matchRefPattern(pattern string, name plumbing.ReferenceName) bool {
+	candidates := []string{name.String(), name.Short()}
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/"} {
+		if strings.HasPrefix(name.String(), prefix) {
+			candidates = append(candidates, strings.TrimPrefix(name.String(), prefix))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if ok, _ := path.Match(pattern, candidate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isGlobPattern(ref string) bool {
+	return strings.ContainsAny(ref, "*?[")
+}
@@ -0,0 +1,312 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image/color"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gonum.org/v1/plot"
+)
+
+// maxReportContributors limits the rows of the top contributors table.
+const maxReportContributors = 25
+
+// ReportFilter is a setting shown in the summary of a report.
+type ReportFilter struct {
+	Name  string
+	Value string
+}
+
+// ReportSummary describes how the activity of a report was analyzed.
+type ReportSummary struct {
+	Title   string
+	Mode    string
+	Window  string // Description of the analyzed date window
+	Filters []ReportFilter
+}
+
+// HTMLReport collects charts as inline SVG and writes them to a single,
+// self-contained HTML file together with summary tables.
+type HTMLReport struct {
+	charts []reportChart
+}
+
+type reportChart struct {
+	ID    string
+	Title string
+	SVG   template.HTML
+}
+
+type reportTotal struct {
+	Name    string
+	Value   int
+	Added   int
+	Deleted int
+	Count   int // Developers of a repository, repositories of a developer
+}
+
+func NewHTMLReport() *HTMLReport {
+	return &HTMLReport{}
+}
+
+func (r *HTMLReport) StackedBarChart(data map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error {
+	p, err := newStackedBarChart(data, labels, title, xLabel, yLabel)
+	if err != nil {
+		return err
+	}
+	return r.addChart(p, title, filename)
+}
+
+func (r *HTMLReport) DivergingBarChart(additions, deletions map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error {
+	p, err := newDivergingBarChart(additions, deletions, labels, title, xLabel, yLabel)
+	if err != nil {
+		return err
+	}
+	return r.addChart(p, title, filename)
+}
+
+func (r *HTMLReport) PunchCard(data []int, title, filename string, c color.Color) error {
+	p, err := newPunchCard(data, title, c)
+	if err != nil {
+		return err
+	}
+	return r.addChart(p, title, filename)
+}
+
+func (r *HTMLReport) TimelineChart(data map[string]map[string]int, periods []string, title, filename, yLabel string) error {
+	p, err := newTimelineChart(data, periods, title, yLabel)
+	if err != nil {
+		return err
+	}
+	return r.addChart(p, title, filename)
+}
+
+// addChart renders p as SVG. The chart's file name, without extension, is
+// used as its anchor.
+func (r *HTMLReport) addChart(p *plot.Plot, title, filename string) error {
+	writer, err := p.WriterTo(chartWidth, chartHeight, "svg")
+	if err != nil {
+		return fmt.Errorf("could not render %s: %w", title, err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := writer.WriteTo(&buf); err != nil {
+		return fmt.Errorf("could not render %s: %w", title, err)
+	}
+
+	// Drop the XML prolog, which is not valid inside HTML
+	svg := buf.String()
+	if i := strings.Index(svg, "<svg"); i > 0 {
+		svg = svg[i:]
+	}
+
+	// The date window of the second line is part of the summary
+	title, _, _ = strings.Cut(title, "\n")
+	id := sanitizeFileName(strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	r.charts = append(r.charts, reportChart{ID: id, Title: title, SVG: template.HTML(svg)})
+	return nil
+}
+
+// Write writes the report with all collected charts to filename.
+func (r *HTMLReport) Write(filename string, combinedActivity *CombinedCommitActivity, summary ReportSummary) error {
+	slog.Info("Writing report", "file", filename, "charts", len(r.charts))
+
+	first, last := activityRange(combinedActivity)
+	contributors := developerTotals(combinedActivity)
+
+	data := struct {
+		ReportSummary
+		Generated     string
+		Partial       bool
+		ValueLabel    string
+		LinesMode     bool
+		FirstActivity string
+		LastActivity  string
+		Contributors  []reportTotal
+		Omitted       int
+		Repositories  []reportTotal
+		Charts        []reportChart
+	}{
+		ReportSummary: summary,
+		Generated:     time.Now().Format("2006-01-02 15:04"),
+		Partial:       combinedActivity.Partial,
+		ValueLabel:    ValueLabel(summary.Mode),
+		LinesMode:     IsLinesMode(summary.Mode),
+		FirstActivity: first,
+		LastActivity:  last,
+		Contributors:  contributors[:min(len(contributors), maxReportContributors)],
+		Omitted:       max(len(contributors)-maxReportContributors, 0),
+		Repositories:  repositoryTotals(combinedActivity),
+		Charts:        r.charts,
+	}
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("could not render report: %w", err)
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0o644)
+}
+
+// activityRange returns the first and last calendar day with activity.
+func activityRange(combinedActivity *CombinedCommitActivity) (first, last string) {
+	for _, repoActivity := range combinedActivity.Repos {
+		for _, days := range repoActivity.Activity.Days {
+			for day, value := range days {
+				if value == 0 {
+					continue
+				}
+				if first == "" || day < first {
+					first = day
+				}
+				if day > last {
+					last = day
+				}
+			}
+		}
+	}
+	return first, last
+}
+
+// developerTotals sums the activity of each developer over all repositories,
+// most active first.
+func developerTotals(combinedActivity *CombinedCommitActivity) []reportTotal {
+	totals := make(map[string]*reportTotal)
+	for _, repoActivity := range combinedActivity.Repos {
+		for developer := range repoActivity.Activity.Weekdays {
+			total := totals[developer]
+			if total == nil {
+				total = &reportTotal{Name: developer}
+				totals[developer] = total
+			}
+			addTotals(total, repoActivity.Activity, developer)
+			total.Count++
+		}
+	}
+
+	result := make([]reportTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Value != result[j].Value {
+			return result[i].Value > result[j].Value
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// repositoryTotals sums the activity of each repository.
+func repositoryTotals(combinedActivity *CombinedCommitActivity) []reportTotal {
+	result := make([]reportTotal, 0, len(combinedActivity.Repos))
+	for _, repoActivity := range combinedActivity.Repos {
+		total := reportTotal{Name: repoActivity.RepoName}
+		for developer := range repoActivity.Activity.Weekdays {
+			addTotals(&total, repoActivity.Activity, developer)
+			total.Count++
+		}
+		result = append(result, total)
+	}
+	return result
+}
+
+func addTotals(total *reportTotal, activity *CommitActivity, developer string) {
+	total.Value += sum(activity.Weekdays[developer])
+	if activity.Additions != nil {
+		total.Added += sum(activity.Additions.Weekdays[developer])
+		total.Deleted += sum(activity.Deletions.Weekdays[developer])
+	}
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 1200px; padding: 0 1em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+nav ol { columns: 2; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+td.number { text-align: right; font-variant-numeric: tabular-nums; }
+figure { margin: 2em 0; }
+figure svg { width: 100%; height: auto; }
+.muted { color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Generated {{.Generated}}</p>
+
+<nav>
+<h2>Contents</h2>
+<ol>
+<li><a href="#summary">Summary</a></li>
+<li><a href="#contributors">Top contributors</a></li>
+<li><a href="#repositories">Repositories</a></li>
+{{- range .Charts}}
+<li><a href="#{{.ID}}">{{.Title}}</a></li>
+{{- end}}
+</ol>
+</nav>
+
+<h2 id="summary">Summary</h2>
+<table>
+<tr><th>Mode</th><td>{{.Mode}} ({{.ValueLabel}})</td></tr>
+<tr><th>Date window</th><td>{{.Window}}</td></tr>
+<tr><th>Activity</th><td>{{if .FirstActivity}}{{.FirstActivity}} to {{.LastActivity}}{{else}}none{{end}}</td></tr>
+{{- if .Partial}}
+<tr><th>Partial data</th><td>The analysis was interrupted; charts and tables are incomplete.</td></tr>
+{{- end}}
+{{- range .Filters}}
+<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+
+<h2 id="contributors">Top contributors</h2>
+<table>
+<tr><th>#</th><th>Developer</th><th>{{.ValueLabel}}</th>{{if .LinesMode}}<th>Added</th><th>Deleted</th>{{end}}<th>Repositories</th></tr>
+{{- $lines := .LinesMode}}
+{{- range $i, $c := .Contributors}}
+<tr><td class="number">{{inc $i}}</td><td>{{$c.Name}}</td><td class="number">{{$c.Value}}</td>{{if $lines}}<td class="number">{{$c.Added}}</td><td class="number">{{$c.Deleted}}</td>{{end}}<td class="number">{{$c.Count}}</td></tr>
+{{- end}}
+</table>
+{{- if .Omitted}}
+<p class="muted">{{.Omitted}} more developers not shown.</p>
+{{- end}}
+
+<h2 id="repositories">Repositories</h2>
+<table>
+<tr><th>Repository</th><th>{{.ValueLabel}}</th>{{if .LinesMode}}<th>Added</th><th>Deleted</th>{{end}}<th>Developers</th></tr>
+{{- range .Repositories}}
+<tr><td>{{.Name}}</td><td class="number">{{.Value}}</td>{{if $lines}}<td class="number">{{.Added}}</td><td class="number">{{.Deleted}}</td>{{end}}<td class="number">{{.Count}}</td></tr>
+{{- end}}
+</table>
+
+{{- range .Charts}}
+<figure id="{{.ID}}">
+<h2>{{.Title}}</h2>
+{{.SVG}}
+</figure>
+{{- end}}
+</body>
+</html>
+`))
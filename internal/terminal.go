@@ -0,0 +1,305 @@
+package internal
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultTerminalWidth is used if the width of the terminal is unknown.
+const defaultTerminalWidth = 100
+
+// segmentRunes tell the groups of a stacked bar apart without colors.
+var segmentRunes = []rune{'█', '▓', '▒', '░', '▚', '▞', '▖', '▗'}
+
+// punchCardRunes show increasing activity in a punch card cell.
+var punchCardRunes = []rune{'·', '▪', '◆', '●'}
+
+// TerminalRenderer draws charts as Unicode block bar charts, with one row per
+// bucket and one segment per stacking group.
+type TerminalRenderer struct {
+	w     io.Writer
+	width int
+	color bool
+}
+
+// NewTerminalRenderer returns a renderer writing to w. Colors are only used
+// if w is a terminal that supports them, see TerminalSupportsColor.
+func NewTerminalRenderer(w io.Writer) *TerminalRenderer {
+	width := defaultTerminalWidth
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 40 {
+		width = columns
+	}
+
+	file, _ := w.(*os.File)
+	return &TerminalRenderer{w: w, width: width, color: TerminalSupportsColor(file)}
+}
+
+// TerminalSupportsColor reports whether ANSI colors should be written to f:
+// it must be a terminal, and neither NO_COLOR nor TERM=dumb may be set.
+func TerminalSupportsColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+func (tr *TerminalRenderer) StackedBarChart(data map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error {
+	groups := sortedKeys(data)
+	labelWidth := maxWidth(labels)
+	barWidth := tr.barWidth(labelWidth)
+
+	maxTotal := 0
+	for _, label := range labels {
+		total := 0
+		for _, group := range groups {
+			total += max(data[group][label], 0)
+		}
+		maxTotal = max(maxTotal, total)
+	}
+
+	tr.printTitle(title, yLabel)
+	for _, label := range labels {
+		values := make([]int, len(groups))
+		total := 0
+		for i, group := range groups {
+			values[i] = data[group][label]
+			total += values[i]
+		}
+		fmt.Fprintf(tr.w, "%s │%s %d\n", padRight(label, labelWidth), tr.stackedBar(values, maxTotal, barWidth), total)
+	}
+	tr.printLegend(groups)
+	return nil
+}
+
+func (tr *TerminalRenderer) DivergingBarChart(additions, deletions map[string]map[string]int, labels []string, title, filename, xLabel, yLabel string) error {
+	groups := make(map[string]bool)
+	for group := range additions {
+		groups[group] = true
+	}
+	for group := range deletions {
+		groups[group] = true
+	}
+	sortedGroups := sortedKeys(groups)
+
+	labelWidth := maxWidth(labels)
+	// Deletions extend to the left, additions to the right of the axis
+	halfWidth := max(tr.barWidth(labelWidth)/2, 1)
+
+	maxTotal := 0
+	for _, label := range labels {
+		added, deleted := 0, 0
+		for _, group := range sortedGroups {
+			added += additions[group][label]
+			deleted += deletions[group][label]
+		}
+		maxTotal = max(maxTotal, added, deleted)
+	}
+
+	tr.printTitle(title, yLabel)
+	for _, label := range labels {
+		added := make([]int, len(sortedGroups))
+		deleted := make([]int, len(sortedGroups))
+		totalAdded, totalDeleted := 0, 0
+		for i, group := range sortedGroups {
+			added[i] = additions[group][label]
+			deleted[i] = deletions[group][label]
+			totalAdded += added[i]
+			totalDeleted += deleted[i]
+		}
+
+		left := tr.stackedBar(deleted, maxTotal, halfWidth)
+		fmt.Fprintf(tr.w, "%s %7s %s%s│%s %s\n",
+			padRight(label, labelWidth),
+			fmt.Sprintf("-%d", totalDeleted),
+			strings.Repeat(" ", halfWidth-visibleWidth(left)),
+			reverseSegments(left),
+			tr.stackedBar(added, maxTotal, halfWidth),
+			fmt.Sprintf("+%d", totalAdded),
+		)
+	}
+	tr.printLegend(sortedGroups)
+	return nil
+}
+
+func (tr *TerminalRenderer) PunchCard(data []int, title, filename string, c color.Color) error {
+	maxValue := 0
+	for _, value := range data {
+		maxValue = max(maxValue, abs(value))
+	}
+
+	weekdays := WeekdayLabels()
+	labelWidth := maxWidth(weekdays)
+
+	tr.printTitle(title, "")
+	header := make([]string, 24)
+	for hour := range header {
+		header[hour] = fmt.Sprintf("%-3d", hour)
+	}
+	fmt.Fprintf(tr.w, "%s  %s\n", strings.Repeat(" ", labelWidth), strings.TrimRight(strings.Join(header, ""), " "))
+
+	for weekday, label := range weekdays {
+		var row strings.Builder
+		for hour := 0; hour < 24; hour++ {
+			value := abs(data[weekday*24+hour])
+			if value == 0 || maxValue == 0 {
+				row.WriteString("   ")
+				continue
+			}
+			// Like the image punch card, the area is proportional to the value
+			level := int(math.Sqrt(float64(value)/float64(maxValue)) * float64(len(punchCardRunes)-1))
+			row.WriteString(tr.colored(string(punchCardRunes[level]), c) + "  ")
+		}
+		fmt.Fprintln(tr.w, strings.TrimRight(padRight(label, labelWidth)+"  "+row.String(), " "))
+	}
+	fmt.Fprintln(tr.w)
+	return nil
+}
+
+func (tr *TerminalRenderer) TimelineChart(data map[string]map[string]int, periods []string, title, filename, yLabel string) error {
+	return tr.StackedBarChart(data, periods, title, filename, "Date", yLabel)
+}
+
+// barWidth returns the space left for bars next to labels and totals.
+func (tr *TerminalRenderer) barWidth(labelWidth int) int {
+	return max(tr.width-labelWidth-12, 10)
+}
+
+// stackedBar draws one segment per value, scaled so that maxTotal fills
+// width. Segments are rounded on their cumulative end so that the bar length
+// matches the total. Negative values are not drawn.
+func (tr *TerminalRenderer) stackedBar(values []int, maxTotal, width int) string {
+	if maxTotal <= 0 {
+		return ""
+	}
+
+	var bar strings.Builder
+	cumulative, drawn := 0, 0
+	for i, value := range values {
+		if value <= 0 {
+			continue
+		}
+		cumulative += value
+		end := int(math.Round(float64(cumulative) / float64(maxTotal) * float64(width)))
+		if end <= drawn {
+			continue
+		}
+		bar.WriteString(tr.segment(i, end-drawn))
+		drawn = end
+	}
+	return bar.String()
+}
+
+// segment draws n cells of the i-th group, in its palette color or with its
+// own block character.
+func (tr *TerminalRenderer) segment(i, n int) string {
+	if tr.color {
+		return tr.colored(strings.Repeat("█", n), colorPalette[i%len(colorPalette)])
+	}
+	return strings.Repeat(string(segmentRunes[i%len(segmentRunes)]), n)
+}
+
+func (tr *TerminalRenderer) colored(s string, c color.Color) string {
+	if !tr.color {
+		return s
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", r>>8, g>>8, b>>8, s)
+}
+
+// printTitle underlines the first line of title. The unit of the values and
+// further lines of title follow the underline, so that the unit does not
+// clash with a suffix of the title such as the stacking.
+func (tr *TerminalRenderer) printTitle(title, unit string) {
+	title, subtitle, _ := strings.Cut(title, "\n")
+	fmt.Fprintf(tr.w, "%s\n%s\n", title, strings.Repeat("─", utf8.RuneCountInString(title)))
+	if unit != "" {
+		fmt.Fprintln(tr.w, unit)
+	}
+	if subtitle != "" {
+		fmt.Fprintln(tr.w, subtitle)
+	}
+}
+
+func (tr *TerminalRenderer) printLegend(groups []string) {
+	if len(groups) > 1 {
+		entries := make([]string, len(groups))
+		for i, group := range groups {
+			entries[i] = tr.segment(i, 2) + " " + group
+		}
+		fmt.Fprintf(tr.w, "Legend: %s\n", strings.Join(entries, "  "))
+	}
+	fmt.Fprintln(tr.w)
+}
+
+// reverseSegments mirrors a bar so that it grows to the left. Escape
+// sequences are kept together with the cells they color.
+func reverseSegments(bar string) string {
+	var parts []string
+	for len(bar) > 0 {
+		if strings.HasPrefix(bar, "\x1b[") {
+			end := strings.Index(bar, "\x1b[0m")
+			if end < 0 {
+				break
+			}
+			end += len("\x1b[0m")
+			parts = append(parts, bar[:end])
+			bar = bar[end:]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(bar)
+		parts = append(parts, bar[:size])
+		bar = bar[size:]
+	}
+
+	var reversed strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		reversed.WriteString(parts[i])
+	}
+	return reversed.String()
+}
+
+// visibleWidth returns the number of cells s takes up on the terminal.
+func visibleWidth(s string) int {
+	width := 0
+	for len(s) > 0 {
+		if strings.HasPrefix(s, "\x1b[") {
+			end := strings.IndexByte(s, 'm')
+			if end < 0 {
+				break
+			}
+			s = s[end+1:]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		width++
+	}
+	return width
+}
+
+func maxWidth(labels []string) int {
+	width := 0
+	for _, label := range labels {
+		width = max(width, utf8.RuneCountInString(label))
+	}
+	return width
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// DayLayout is the layout of the calendar day keys in CommitActivity.Days.
+const DayLayout = "2006-01-02"
+
+// maxTimelineTicks limits the number of labeled periods on the X axis.
+const maxTimelineTicks = 24
+
+// TimelinePeriodStart truncates t to the start of its day, ISO week (Monday)
+// or month.
+func TimelinePeriodStart(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7 // Days since Monday
+		return day.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// TimelinePeriodLabel formats the start of a period for display.
+func TimelinePeriodLabel(t time.Time, granularity string) string {
+	switch granularity {
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "month":
+		return t.Format("2006-01")
+	default:
+		return t.Format(DayLayout)
+	}
+}
+
+func nextTimelinePeriod(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// TimelinePeriods returns the labels of all periods from start to end. Zero
+// bounds are taken from the earliest and latest day with activity.
+func TimelinePeriods(combinedActivity *CombinedCommitActivity, granularity string, start, end time.Time) []string {
+	first, last := start, end
+	for _, repoActivity := range combinedActivity.Repos {
+		for _, days := range repoActivity.Activity.Days {
+			for day := range days {
+				t, err := time.Parse(DayLayout, day)
+				if err != nil {
+					continue
+				}
+				if start.IsZero() && (first.IsZero() || t.Before(first)) {
+					first = t
+				}
+				if end.IsZero() && (last.IsZero() || t.After(last)) {
+					last = t
+				}
+			}
+		}
+	}
+	if first.IsZero() || last.IsZero() {
+		return nil
+	}
+
+	var periods []string
+	lastPeriod := TimelinePeriodStart(last, granularity)
+	for t := TimelinePeriodStart(first, granularity); !t.After(lastPeriod); t = nextTimelinePeriod(t, granularity) {
+		periods = append(periods, TimelinePeriodLabel(t, granularity))
+	}
+	return periods
+}
+
+// GenerateTimelineChart renders activity per calendar day, week or month over
+// the whole date range, stacked like the cyclic charts.
+func GenerateTimelineChart(
+	renderer ChartRenderer,
+	combinedActivity *CombinedCommitActivity,
+	mode, stacking, granularity, outputPrefix, format string,
+	start, end time.Time,
+) error {
+	slog.Info("Generating timeline chart", "output_prefix", outputPrefix, "granularity", granularity, "stacking", stacking)
+
+	periods := TimelinePeriods(combinedActivity, granularity, start, end)
+	if len(periods) == 0 {
+		slog.Warn("No activity to show in timeline chart")
+		return nil
+	}
+
+	groupedData := make(map[string]map[string]int)
+
+	for _, repoActivity := range combinedActivity.Repos {
+		for developer, days := range repoActivity.Activity.Days {
+			key := "All"
+			switch stacking {
+			case "dev", "developer":
+				key = developer
+			case "repo", "repository":
+				key = repoActivity.RepoName
+			}
+			if groupedData[key] == nil {
+				groupedData[key] = make(map[string]int)
+			}
+
+			for day, value := range days {
+				t, err := time.Parse(DayLayout, day)
+				if err != nil {
+					continue
+				}
+				period := TimelinePeriodLabel(TimelinePeriodStart(t, granularity), granularity)
+				groupedData[key][period] += value
+			}
+		}
+	}
+
+	yLabel := ValueLabel(mode)
+
+	chartTitle := fmt.Sprintf("Activity per %s", granularity)
+	fileName := fmt.Sprintf("%s_timeline_%s.%s", outputPrefix, granularity, format)
+	if stacking != "" {
+		chartTitle = fmt.Sprintf("%s (%s)", chartTitle, stacking)
+		fileName = fmt.Sprintf("%s_timeline_%s_%s.%s", outputPrefix, granularity, stacking, format)
+	}
+
+	return renderer.TimelineChart(groupedData, periods, decorateTitle(combinedActivity, chartTitle), fileName, yLabel)
+}
+
+// CreateTimelineChart creates a stacked bar chart over consecutive periods.
+// Unlike CreateStackedBarChart, bar widths shrink with the number of periods
+// and only a subset of the periods is labeled.
+func CreateTimelineChart(data map[string]map[string]int, periods []string, title, filename, yLabel string) error {
+	p, err := newTimelineChart(data, periods, title, yLabel)
+	if err != nil {
+		return err
+	}
+	return p.Save(chartWidth, chartHeight, filename)
+}
+
+func newTimelineChart(data map[string]map[string]int, periods []string, title, yLabel string) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "Date"
+	p.Y.Label.Text = yLabel
+
+	barWidth := min(vg.Points(20), 0.8*(chartWidth-vg.Inch)/vg.Length(len(periods)))
+
+	var previousBars *plotter.BarChart
+	for j, category := range sortedKeys(data) {
+		values := make(plotter.Values, len(periods))
+		for i, period := range periods {
+			values[i] = float64(data[category][period])
+		}
+
+		bars, err := plotter.NewBarChart(values, barWidth)
+		if err != nil {
+			return nil, fmt.Errorf("could not create bar chart for %s: %w", category, err)
+		}
+
+		bars.LineStyle.Width = vg.Length(0)
+		bars.Color = colorPalette[j%len(colorPalette)]
+
+		if previousBars != nil {
+			bars.StackOn(previousBars)
+		}
+
+		p.Add(bars)
+		p.Legend.Add(category, bars)
+
+		previousBars = bars
+	}
+
+	step := max(int(math.Ceil(float64(len(periods))/maxTimelineTicks)), 1)
+	var ticks []plot.Tick
+	for i, period := range periods {
+		if i%step == 0 {
+			ticks = append(ticks, plot.Tick{Value: float64(i), Label: period})
+		}
+	}
+	p.X.Tick.Marker = plot.ConstantTicks(ticks)
+	p.Legend.Top = true
+
+	return p, nil
+}
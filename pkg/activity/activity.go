@@ -0,0 +1,262 @@
+// Package activity analyzes when developers work on Git repositories and
+// renders the results as charts, reports or data files.
+//
+// A typical use analyzes a set of repositories and saves the charts:
+//
+//	result, err := activity.Analyze(ctx, activity.Options{
+//		Repos: []string{"./repo1", "./repo2"},
+//		Mode:  "lines",
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = activity.RenderCharts(result, activity.ChartOptions{Format: "svg"})
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"git-activity/internal"
+)
+
+type (
+	// DeveloperAliases maps lowercase emails to developer names.
+	DeveloperAliases = internal.DeveloperAliases
+	// DeveloperZones maps developer names to the time zone they work in.
+	DeveloperZones = internal.DeveloperZones
+	// Mailmap canonicalizes author identities like Git's .mailmap.
+	Mailmap = internal.Mailmap
+	// CommitActivity holds the activity of one repository per developer.
+	CommitActivity = internal.CommitActivity
+	// CombinedActivity holds the activity of all analyzed repositories.
+	CombinedActivity = internal.CombinedCommitActivity
+	// RepoError is the failure to analyze one repository.
+	RepoError = internal.RepoError
+	// AnalysisError lists the repositories that could not be analyzed.
+	AnalysisError = internal.AnalysisError
+)
+
+// Modes lists the supported analysis modes.
+var Modes = []string{"commits", "lines", "added", "deleted", "net"}
+
+// FileClasses lists the classes of files that can be excluded from line
+// counts.
+var FileClasses = internal.FileClasses
+
+// Options controls which history is analyzed and how it is counted. The zero
+// value of every field is a sensible default.
+type Options struct {
+	Repos       []string  // Paths of the repositories to analyze
+	Refs        []string  // Branches, tags, revisions or glob patterns; HEAD if empty
+	AllBranches bool      // Analyze all local and remote-tracking branches
+	FirstParent bool      // Only follow the first parent of merge commits
+	Merges      string    // "include" (default), "exclude" or "only" merge commits
+	Start       time.Time // Only count commits from this instant on, if set
+	End         time.Time // Only count commits up to and including this instant, if set
+	SinceTag    string    // Only count commits after this tag's commit, resolved per repository; replaces Start
+	UntilTag    string    // Only count commits up to this tag's commit, resolved per repository; replaces End
+
+	// Only count commits from this day on and up to and including this day,
+	// in YYYY-MM-DD format. Unlike Start and End, days are compared with
+	// each commit's date in the Timezone of the buckets, so that they cover
+	// whole days.
+	StartDate  string
+	EndDate    string
+	DateSource string // "author" (default) or "committer" date
+
+	// Time zone of the hour and weekday buckets: "author" (default) for each
+	// author's own offset, "people" for the per-developer Zones, or an IANA
+	// zone name.
+	Timezone string
+	Zones    DeveloperZones
+
+	Mode    string           // One of Modes; "commits" if empty
+	Aliases DeveloperAliases // Email -> developer name
+	Mailmap *Mailmap         // Extra entries applied on top of each repository's .mailmap
+	Unknown string           // Authors missing from Aliases: "author-name" (default), "email", "unknown" or "drop"
+
+	Include []string // Only count files matching these gitignore-style patterns
+	Exclude []string // Do not count files matching these gitignore-style patterns
+
+	// File classes not counted in the line modes; all FileClasses if nil.
+	// Use an empty, non-nil slice to count all files.
+	ExcludeClasses []string
+
+	Jobs      int    // Number of parallel workers; number of CPUs if zero
+	CacheDir  string // Directory of the per-commit cache; caching is disabled if empty
+	KeepGoing bool   // Skip repositories that fail instead of stopping
+
+	Progress *ProgressTracker // Tracks the commits walked, see ShowProgress; may be nil
+}
+
+// DefaultCacheDir returns the directory caches are stored in by default.
+func DefaultCacheDir() string {
+	return internal.DefaultCacheDir()
+}
+
+// withDefaults returns a copy of the options with empty fields set to their
+// defaults.
+func (o Options) withDefaults() Options {
+	if o.Mode == "" {
+		o.Mode = "commits"
+	}
+	if o.Merges == "" {
+		o.Merges = "include"
+	}
+	if o.DateSource == "" {
+		o.DateSource = "author"
+	}
+	if o.Timezone == "" {
+		o.Timezone = "author"
+	}
+	if o.Unknown == "" {
+		o.Unknown = "author-name"
+	}
+	if o.ExcludeClasses == nil {
+		o.ExcludeClasses = FileClasses
+	}
+	return o
+}
+
+// Validate reports the first invalid option.
+func (o Options) Validate() error {
+	o = o.withDefaults()
+
+	if len(o.Repos) == 0 {
+		return errors.New("no repositories given")
+	}
+	if !slices.Contains(Modes, o.Mode) {
+		return fmt.Errorf("invalid mode '%s'. Supported modes are 'commits', 'lines', 'added', 'deleted' or 'net'", o.Mode)
+	}
+	if o.Unknown != "author-name" && o.Unknown != "email" && o.Unknown != "unknown" && o.Unknown != "drop" {
+		return fmt.Errorf("invalid unknown policy '%s'. Supported policies are 'author-name', 'email', 'unknown' or 'drop'", o.Unknown)
+	}
+	if o.DateSource != "author" && o.DateSource != "committer" {
+		return fmt.Errorf("invalid date source '%s'. Supported sources are 'author' and 'committer'", o.DateSource)
+	}
+	if o.Merges != "include" && o.Merges != "exclude" && o.Merges != "only" {
+		return fmt.Errorf("invalid merges policy '%s'. Supported policies are 'include', 'exclude' or 'only'", o.Merges)
+	}
+	for _, class := range o.ExcludeClasses {
+		if !slices.Contains(FileClasses, class) {
+			return fmt.Errorf("invalid file class '%s'. Supported classes are 'vendored', 'generated', 'lockfile' or 'binary'", class)
+		}
+	}
+	for _, day := range []string{o.StartDate, o.EndDate} {
+		if _, err := time.Parse(internal.DayLayout, day); day != "" && err != nil {
+			return fmt.Errorf("invalid date '%s'. Use YYYY-MM-DD", day)
+		}
+	}
+	if countSet(!o.Start.IsZero(), o.StartDate != "", o.SinceTag != "") > 1 {
+		return errors.New("only one of a start date, start time and since tag can be given")
+	}
+	if countSet(!o.End.IsZero(), o.EndDate != "", o.UntilTag != "") > 1 {
+		return errors.New("only one of an end date, end time and until tag can be given")
+	}
+	if start, end := o.windowTimes(); !start.IsZero() && !end.IsZero() && start.After(end) {
+		return errors.New("start date cannot be after end date")
+	}
+	if _, _, err := o.location(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// location returns the zone commit times are converted to and the
+// per-developer zones, according to Timezone.
+func (o Options) location() (*time.Location, DeveloperZones, error) {
+	switch o.Timezone {
+	case "", "author":
+		return nil, nil, nil
+	case "people":
+		if len(o.Zones) == 0 {
+			return nil, nil, errors.New("timezone 'people' requires time zones in the people file, e.g. 'Alice|alice@example.com|tz=Europe/Berlin'")
+		}
+		return nil, o.Zones, nil
+	}
+
+	location, err := time.LoadLocation(o.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone '%s'. Use 'author', 'people' or an IANA time zone such as 'Europe/Berlin': %w", o.Timezone, err)
+	}
+	return location, nil, nil
+}
+
+// Result is the activity of all analyzed repositories.
+type Result struct {
+	// Name derived from the repository names, used as the default prefix of
+	// output files
+	Name     string
+	Options  Options // Options with defaults applied
+	Activity *CombinedActivity
+}
+
+// Partial reports whether the analysis was interrupted, so that the result is
+// incomplete. Charts rendered from a partial result are labeled as such.
+func (r *Result) Partial() bool {
+	return r.Activity.Partial
+}
+
+// UnmappedIdentities returns the commit counts of all authors that were not
+// found in the aliases, summed over all repositories.
+func (r *Result) UnmappedIdentities() map[string]int {
+	return r.Activity.UnmappedIdentities()
+}
+
+// Analyze walks the history of all repositories and aggregates their
+// activity. If a repository fails, an *AnalysisError is returned; with
+// Options.KeepGoing, it is returned together with the result of all other
+// repositories.
+//
+// Analyze stops promptly once ctx is done. The activity collected so far is
+// then returned as a partial result (see Result.Partial) together with an
+// error wrapping the context's error.
+func Analyze(ctx context.Context, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	opts = opts.withDefaults()
+
+	location, zones, err := opts.location()
+	if err != nil {
+		return nil, err
+	}
+
+	name, combinedActivity, err := internal.AnalyzeRepositories(ctx, opts.Repos, internal.AnalyzeOptions{
+		Start:       opts.Start,
+		End:         opts.End,
+		StartDay:    opts.StartDate,
+		EndDay:      opts.EndDate,
+		SinceTag:    opts.SinceTag,
+		UntilTag:    opts.UntilTag,
+		Mode:        opts.Mode,
+		Aliases:     opts.Aliases,
+		Location:    location,
+		Zones:       zones,
+		Refs:        opts.Refs,
+		AllBranches: opts.AllBranches,
+		FirstParent: opts.FirstParent,
+		Merges:      opts.Merges,
+		DateSource:  opts.DateSource,
+		Mailmap:     opts.Mailmap,
+		Unknown:     opts.Unknown,
+		Jobs:        opts.Jobs,
+		KeepGoing:   opts.KeepGoing,
+		Progress:    opts.Progress,
+		CacheDir:    opts.CacheDir,
+		Paths:       internal.NewPathFilter(opts.Include, opts.Exclude),
+		Classifier:  internal.NewFileClassifier(opts.ExcludeClasses),
+	})
+	if err != nil && (combinedActivity == nil || !opts.KeepGoing && !combinedActivity.Partial) {
+		return nil, err
+	}
+	if opts.windowRestricted() {
+		combinedActivity.Window = opts.Window()
+	}
+
+	return &Result{Name: name, Options: opts, Activity: combinedActivity}, err
+}
@@ -0,0 +1,26 @@
+package activity
+
+import (
+	"time"
+
+	"git-activity/internal"
+)
+
+// CacheSummary describes the cache file of one repository.
+type CacheSummary = internal.CacheSummary
+
+// ListCaches returns a summary of all cache files in cacheDir.
+func ListCaches(cacheDir string) ([]CacheSummary, error) {
+	return internal.ListCaches(cacheDir)
+}
+
+// PruneCaches removes caches of repositories that no longer exist, are
+// unreadable, or have not been used for longer than maxAge (if non-zero).
+func PruneCaches(cacheDir string, maxAge time.Duration) ([]CacheSummary, error) {
+	return internal.PruneCaches(cacheDir, maxAge)
+}
+
+// ClearCaches removes all cache files in cacheDir.
+func ClearCaches(cacheDir string) ([]CacheSummary, error) {
+	return internal.ClearCaches(cacheDir)
+}
@@ -0,0 +1,137 @@
+package activity
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	shortAgoPattern    = regexp.MustCompile(`^(\d+)\s*([dwmy])$`)
+	longAgoPattern     = regexp.MustCompile(`^(\d+)\s+(day|week|month|year)s?\s+ago$`)
+	namedPeriodPattern = regexp.MustCompile(`^(this|last)[- ](week|month|quarter|year)$`)
+	quarterPattern     = regexp.MustCompile(`^(\d{4})-q([1-4])$`)
+	isoWeekPattern     = regexp.MustCompile(`^(\d{4})-w(\d{2})$`)
+)
+
+// ParsePeriod parses a date expression into the first and last day of the
+// period it denotes, as midnight UTC. Supported expressions are:
+//
+//   - dates: 2024-03-15, a month 2024-03 or a year 2024
+//   - days relative to now: today, yesterday, 30d, 2w, 6m, 1y or
+//     "6 months ago"
+//   - calendar periods relative to now: this-week, last-week, this-month,
+//     last-month, this-quarter, last-quarter, this-year and last-year
+//   - quarters such as 2024-Q3 and ISO weeks such as 2024-W12
+//
+// Weeks start on Monday. A start date is the first day of its period and an
+// end date the last one, e.g. "--start last-quarter --end last-quarter"
+// covers the whole quarter.
+func ParsePeriod(expr string, now time.Time) (first, last time.Time, err error) {
+	original := expr
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := func(t time.Time) (time.Time, time.Time, error) {
+		return t, t, nil
+	}
+
+	switch expr {
+	case "today":
+		return day(today)
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	}
+
+	if m := shortAgoPattern.FindStringSubmatch(expr); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return day(daysAgo(today, n, map[string]string{"d": "day", "w": "week", "m": "month", "y": "year"}[m[2]]))
+	}
+	if m := longAgoPattern.FindStringSubmatch(expr); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return day(daysAgo(today, n, m[2]))
+	}
+	if m := namedPeriodPattern.FindStringSubmatch(expr); m != nil {
+		first, last := currentPeriod(today, m[2])
+		if m[1] == "last" {
+			first, last = currentPeriod(first.AddDate(0, 0, -1), m[2])
+		}
+		return first, last, nil
+	}
+	if m := quarterPattern.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		first := time.Date(year, time.Month(quarter*3-2), 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(0, 3, -1), nil
+	}
+	if m := isoWeekPattern.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		first := isoWeekStart(year, week)
+		if isoYear, isoWeek := first.ISOWeek(); week < 1 || isoYear != year || isoWeek != week {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid date '%s': %d has no week %d", original, year, week)
+		}
+		return first, first.AddDate(0, 0, 6), nil
+	}
+
+	for _, layout := range []struct {
+		format string
+		years  int
+		months int
+	}{
+		{"2006-01-02", 0, 0},
+		{"2006-01", 0, 1},
+		{"2006", 1, 0},
+	} {
+		if first, err := time.Parse(layout.format, expr); err == nil {
+			if layout.years == 0 && layout.months == 0 {
+				return day(first)
+			}
+			return first, first.AddDate(layout.years, layout.months, -1), nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid date '%s'. Use YYYY-MM-DD, a relative date such as '30d' or '6 months ago', a period such as 'last-quarter', or YYYY-Qn or YYYY-Www", original)
+}
+
+// daysAgo returns the day n units before today.
+func daysAgo(today time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "week":
+		return today.AddDate(0, 0, -7*n)
+	case "month":
+		return today.AddDate(0, -n, 0)
+	case "year":
+		return today.AddDate(-n, 0, 0)
+	default:
+		return today.AddDate(0, 0, -n)
+	}
+}
+
+// currentPeriod returns the first and last day of the week, month, quarter
+// or year containing day.
+func currentPeriod(day time.Time, unit string) (first, last time.Time) {
+	switch unit {
+	case "week":
+		first = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return first, first.AddDate(0, 0, 6)
+	case "month":
+		first = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(0, 1, -1)
+	case "quarter":
+		first = time.Date(day.Year(), (day.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(0, 3, -1)
+	default:
+		first = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return first, first.AddDate(1, 0, -1)
+	}
+}
+
+// isoWeekStart returns the Monday of an ISO week. Week 1 is the week
+// containing January 4th.
+func isoWeekStart(year, week int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+	return monday.AddDate(0, 0, 7*(week-1))
+}
@@ -0,0 +1,94 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	// A Friday
+	now := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		expr  string
+		now   time.Time
+		first string
+		last  string
+	}{
+		{"day", "2024-03-15", now, "2024-03-15", "2024-03-15"},
+		{"month", "2024-04", now, "2024-04-01", "2024-04-30"},
+		{"month in leap year", "2024-02", now, "2024-02-01", "2024-02-29"},
+		{"month in common year", "2023-02", now, "2023-02-01", "2023-02-28"},
+		{"last month of year", "2024-12", now, "2024-12-01", "2024-12-31"},
+		{"year", "2024", now, "2024-01-01", "2024-12-31"},
+		{"first quarter", "2024-Q1", now, "2024-01-01", "2024-03-31"},
+		{"last quarter", "2020-Q4", now, "2020-10-01", "2020-12-31"},
+		{"week starting in the year", "2024-W01", now, "2024-01-01", "2024-01-07"},
+		{"week starting in the previous year", "2025-W01", now, "2024-12-30", "2025-01-05"},
+		{"week 53", "2020-W53", now, "2020-12-28", "2021-01-03"},
+		{"week after week 53", "2021-W01", now, "2021-01-04", "2021-01-10"},
+		{"today", "today", now, "2024-03-15", "2024-03-15"},
+		{"yesterday", "yesterday", now, "2024-03-14", "2024-03-14"},
+		{"days", "30d", now, "2024-02-14", "2024-02-14"},
+		{"weeks", "2w", now, "2024-03-01", "2024-03-01"},
+		{"months", "6m", now, "2023-09-15", "2023-09-15"},
+		{"years", "1y", now, "2023-03-15", "2023-03-15"},
+		{"months ago", "6 months ago", now, "2023-09-15", "2023-09-15"},
+		{"day ago", "1 day ago", now, "2024-03-14", "2024-03-14"},
+		{"this week", "this-week", now, "2024-03-11", "2024-03-17"},
+		{"last week", "last-week", now, "2024-03-04", "2024-03-10"},
+		{"this month", "this-month", now, "2024-03-01", "2024-03-31"},
+		{"last month", "last-month", now, "2024-02-01", "2024-02-29"},
+		{"this quarter", "this-quarter", now, "2024-01-01", "2024-03-31"},
+		{"last quarter", "last-quarter", now, "2023-10-01", "2023-12-31"},
+		{"last year", "last year", now, "2023-01-01", "2023-12-31"},
+		{"case and spaces", " Last-Month ", now, "2024-02-01", "2024-02-29"},
+		{"lowercase week", "2024-w01", now, "2024-01-01", "2024-01-07"},
+		{"week on a Monday", "this-week", time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), "2024-03-11", "2024-03-17"},
+		{"week on a Sunday", "this-week", time.Date(2024, time.March, 17, 23, 0, 0, 0, time.UTC), "2024-03-11", "2024-03-17"},
+
+		// Relative dates are taken in the zone of now, not in UTC
+		{"today ahead of UTC", "today", time.Date(2024, time.March, 1, 0, 30, 0, 0, time.FixedZone("", 2*3600)), "2024-03-01", "2024-03-01"},
+		{"month ahead of UTC", "this-month", time.Date(2024, time.March, 1, 0, 30, 0, 0, time.FixedZone("", 2*3600)), "2024-03-01", "2024-03-31"},
+		{"today behind UTC", "today", time.Date(2024, time.February, 29, 23, 30, 0, 0, time.FixedZone("", -5*3600)), "2024-02-29", "2024-02-29"},
+		{"last month behind UTC", "last-month", time.Date(2024, time.February, 29, 23, 30, 0, 0, time.FixedZone("", -5*3600)), "2024-01-01", "2024-01-31"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first, last, err := ParsePeriod(test.expr, test.now)
+			if err != nil {
+				t.Fatalf("ParsePeriod(%q) failed: %v", test.expr, err)
+			}
+			if got := first.Format(time.DateOnly); got != test.first {
+				t.Errorf("ParsePeriod(%q) first = %s, want %s", test.expr, got, test.first)
+			}
+			if got := last.Format(time.DateOnly); got != test.last {
+				t.Errorf("ParsePeriod(%q) last = %s, want %s", test.expr, got, test.last)
+			}
+			if first.Location() != time.UTC || first.Hour() != 0 || last.Location() != time.UTC || last.Hour() != 0 {
+				t.Errorf("ParsePeriod(%q) = %v, %v, want midnight UTC", test.expr, first, last)
+			}
+		})
+	}
+}
+
+func TestParsePeriodInvalid(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+
+	for _, expr := range []string{
+		"",
+		"soon",
+		"2024-13",
+		"2024-02-30",
+		"2024-Q5",
+		"2024-W00",
+		"2021-W53",
+		"2024-03-15T09:30:00+01:00", // Timestamps are parsed by the caller
+	} {
+		if first, last, err := ParsePeriod(expr, now); err == nil {
+			t.Errorf("ParsePeriod(%q) = %v, %v, want an error", expr, first, last)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package activity
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"git-activity/internal"
+)
+
+// LoadPeople parses a people file with one developer per line,
+//
+//	name|alias1|alias2|...|tz=<IANA zone>
+//
+// into a map of aliases to developer names and the time zones declared for
+// developers with a "tz=" field. Lines without aliases are skipped.
+func LoadPeople(filename string) (DeveloperAliases, DeveloperZones, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	aliases, zones := DeveloperAliases{}, DeveloperZones{}
+	if err := AddPeople(aliases, zones, lines); err != nil {
+		return nil, nil, err
+	}
+	return aliases, zones, nil
+}
+
+// AddPeople adds developers given as lines of a people file, see LoadPeople,
+// to aliases and zones. Existing aliases are overridden.
+func AddPeople(aliases DeveloperAliases, zones DeveloperZones, lines []string) error {
+	for _, line := range lines {
+		parts := strings.Split(line, "|")
+		if len(parts) < 2 {
+			continue // Skip invalid lines
+		}
+
+		name := parts[0]
+		for _, alias := range parts[1:] {
+			alias = strings.TrimSpace(alias)
+			if zone, ok := strings.CutPrefix(alias, "tz="); ok {
+				location, err := time.LoadLocation(zone)
+				if err != nil {
+					return fmt.Errorf("invalid time zone for %s: %w", name, err)
+				}
+				zones[name] = location
+				continue
+			}
+			aliases[strings.ToLower(alias)] = name
+		}
+	}
+	return nil
+}
+
+// AddTeams groups developers into teams, given one team per line,
+//
+//	team|member1|member2|...|tz=<IANA zone>
+//
+// where members are developer names from aliases or emails. All aliases of
+// a member are mapped to the team, so that the activity of its members is
+// counted as that of the team. A "tz=" field sets the time zone
+// of the team.
+func AddTeams(aliases DeveloperAliases, zones DeveloperZones, lines []string) error {
+	teams := make(map[string]string) // Member -> team
+	for _, line := range lines {
+		parts := strings.Split(line, "|")
+		if len(parts) < 2 {
+			continue
+		}
+
+		team := parts[0]
+		for _, member := range parts[1:] {
+			member = strings.TrimSpace(member)
+			if zone, ok := strings.CutPrefix(member, "tz="); ok {
+				location, err := time.LoadLocation(zone)
+				if err != nil {
+					return fmt.Errorf("invalid time zone for team %s: %w", team, err)
+				}
+				zones[team] = location
+				continue
+			}
+			if other, ok := teams[member]; ok && other != team {
+				return fmt.Errorf("%s is a member of both %s and %s", member, other, team)
+			}
+			teams[member] = team
+		}
+	}
+
+	developers := make(map[string]bool)
+	for _, name := range aliases {
+		developers[name] = true
+	}
+
+	for member, team := range teams {
+		switch {
+		case developers[member]:
+			// All aliases of the developer are replaced below
+		case strings.Contains(member, "@"):
+			aliases[strings.ToLower(member)] = team
+		default:
+			return fmt.Errorf("team member %s of %s is neither a known developer nor an email", member, team)
+		}
+	}
+	for alias, name := range aliases {
+		if team, ok := teams[name]; ok {
+			aliases[alias] = team
+		}
+	}
+	return nil
+}
+
+// LoadMailmap parses a file in .mailmap format.
+func LoadMailmap(filename string) (*Mailmap, error) {
+	return internal.LoadMailmap(filename)
+}
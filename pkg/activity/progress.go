@@ -0,0 +1,27 @@
+package activity
+
+import (
+	"io"
+
+	"git-activity/internal"
+)
+
+type (
+	// ProgressTracker counts the commits walked by Analyze.
+	ProgressTracker = internal.ProgressTracker
+	// ProgressSnapshot is the state of a ProgressTracker at one point in time.
+	ProgressSnapshot = internal.ProgressSnapshot
+)
+
+// NewProgressTracker returns a tracker to pass as Options.Progress.
+func NewProgressTracker() *ProgressTracker {
+	return internal.NewProgressTracker()
+}
+
+// ShowProgress displays the progress of pt until the returned function is
+// called: as a status line if w is a terminal, and as periodic log records
+// otherwise. The estimated time remaining is based on a quick count of the
+// commits of each repository.
+func ShowProgress(pt *ProgressTracker, w io.Writer) (stop func()) {
+	return internal.ShowProgress(pt, w)
+}
@@ -0,0 +1,151 @@
+package activity
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"git-activity/internal"
+)
+
+// ReportFilter is a setting shown in the summary of an HTML report.
+type ReportFilter = internal.ReportFilter
+
+// ChartOptions controls how the charts of a Result are rendered.
+type ChartOptions struct {
+	// "png" (default) or "svg" for one image file per chart, "html" for a
+	// single report, or "term" to draw the charts on Writer
+	Format string
+	// Stacking groups: "developer", "repository", or "" for a single group
+	Stacking string
+	// Also render a timeline per "day", "week" or "month"
+	Timeline string
+	// Prefix of the output files; Result.Name if empty
+	OutputPrefix string
+	// Destination of "term" charts; os.Stdout if nil
+	Writer io.Writer
+	// Title and settings shown in the HTML report
+	Title   string
+	Filters []ReportFilter
+}
+
+// Validate reports the first invalid option.
+func (o ChartOptions) Validate() error {
+	switch o.Format {
+	case "", "png", "svg", "html", "term":
+	default:
+		return fmt.Errorf("invalid format '%s'. Supported formats are 'png', 'svg', 'html' and 'term'", o.Format)
+	}
+	switch o.Stacking {
+	case "", "repo", "dev", "repository", "developer":
+	default:
+		return fmt.Errorf("invalid bars mode '%s'. Supported modes are 'repository', 'developer', or 'flat'", o.Stacking)
+	}
+	switch o.Timeline {
+	case "", "day", "week", "month":
+	default:
+		return fmt.Errorf("invalid timeline '%s'. Supported timelines are 'day', 'week' or 'month'", o.Timeline)
+	}
+	return nil
+}
+
+// RenderCharts renders the charts of a result: the weekday, hour, month and
+// week charts, punch cards, and optionally a timeline.
+func RenderCharts(result *Result, opts ChartOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if opts.Format == "" {
+		opts.Format = "png"
+	}
+	if opts.Writer == nil {
+		opts.Writer = os.Stdout
+	}
+	prefix := outputPrefix(result, opts.OutputPrefix)
+	mode := result.Options.Mode
+
+	// Charts are written to one file each, collected into a report, or
+	// drawn on the terminal
+	var renderer internal.ChartRenderer = internal.FileRenderer{}
+	var report *internal.HTMLReport
+	switch opts.Format {
+	case "html":
+		report = internal.NewHTMLReport()
+		renderer = report
+	case "term":
+		renderer = internal.NewTerminalRenderer(opts.Writer)
+	}
+
+	err := internal.GenerateCharts(renderer, result.Activity, mode, opts.Stacking, prefix, opts.Format, result.Options.Aliases)
+	if err != nil {
+		return fmt.Errorf("error generating charts: %w", err)
+	}
+
+	if opts.Timeline != "" {
+		start, end := result.Options.windowTimes()
+		err = internal.GenerateTimelineChart(renderer, result.Activity, mode, opts.Stacking, opts.Timeline, prefix, opts.Format, start, end)
+		if err != nil {
+			return fmt.Errorf("error generating timeline chart: %w", err)
+		}
+	}
+
+	if report != nil {
+		title := opts.Title
+		if title == "" {
+			title = fmt.Sprintf("Git activity: %s", strings.Join(result.Options.Repos, ", "))
+		}
+		summary := internal.ReportSummary{
+			Title:   title,
+			Mode:    mode,
+			Window:  result.Options.Window(),
+			Filters: opts.Filters,
+		}
+		if err := report.Write(fmt.Sprintf("%s_report.html", prefix), result.Activity, summary); err != nil {
+			return fmt.Errorf("error writing report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateDataFormats reports the first unsupported export format.
+func ValidateDataFormats(formats []string) error {
+	for _, format := range formats {
+		if format != "csv" && format != "json" {
+			return fmt.Errorf("invalid data format '%s'. Supported formats are 'csv' and 'json'", format)
+		}
+	}
+	return nil
+}
+
+// ExportData writes the activity of a result to "<prefix>_activity.<format>"
+// for every format ("csv" or "json"). The prefix defaults to Result.Name.
+func ExportData(result *Result, prefix string, formats []string) error {
+	if err := ValidateDataFormats(formats); err != nil {
+		return err
+	}
+	return internal.ExportData(result.Activity, exportMetadata(result), outputPrefix(result, prefix), formats)
+}
+
+// WriteCSV writes the activity of a result in long CSV format.
+func WriteCSV(w io.Writer, result *Result) error {
+	return internal.ExportCSV(w, result.Activity)
+}
+
+// WriteJSON writes the activity of a result as JSON.
+func WriteJSON(w io.Writer, result *Result) error {
+	return internal.ExportJSON(w, result.Activity, exportMetadata(result))
+}
+
+func exportMetadata(result *Result) internal.ExportMetadata {
+	start, end := result.Options.windowBounds()
+	return internal.ExportMetadata{Mode: result.Options.Mode, Start: start, End: end}
+}
+
+func outputPrefix(result *Result, prefix string) string {
+	if prefix == "" {
+		return result.Name
+	}
+	return prefix
+}
@@ -0,0 +1,105 @@
+package activity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git-activity/internal"
+)
+
+// Window describes the effective date window of the analysis, e.g.
+// "2024-01-01 to 2024-03-31 (whole days in each author's time zone)".
+func (o Options) Window() string {
+	o = o.withDefaults()
+	if !o.windowRestricted() {
+		return "all history"
+	}
+
+	start, end := "the beginning", "now"
+	switch {
+	case o.StartDate != "":
+		start = o.StartDate
+	case !o.Start.IsZero():
+		start = o.Start.Format(time.RFC3339)
+	case o.SinceTag != "":
+		start = fmt.Sprintf("after tag %s", o.SinceTag)
+	}
+	switch {
+	case o.EndDate != "":
+		end = o.EndDate
+	case !o.End.IsZero():
+		end = o.End.Format(time.RFC3339)
+	case o.UntilTag != "":
+		end = fmt.Sprintf("tag %s", o.UntilTag)
+	}
+
+	var notes []string
+	if o.StartDate != "" || o.EndDate != "" {
+		notes = append(notes, "whole days in "+o.zoneDescription())
+	}
+	if o.SinceTag != "" || o.UntilTag != "" {
+		notes = append(notes, "tags resolved per repository")
+	}
+
+	window := fmt.Sprintf("%s to %s", start, end)
+	if len(notes) > 0 {
+		window += fmt.Sprintf(" (%s)", strings.Join(notes, ", "))
+	}
+	return window
+}
+
+// windowRestricted reports whether any bound of the date window is set.
+func (o Options) windowRestricted() bool {
+	return !o.Start.IsZero() || !o.End.IsZero() || o.StartDate != "" || o.EndDate != "" || o.SinceTag != "" || o.UntilTag != ""
+}
+
+// zoneDescription describes the time zone days are compared in.
+func (o Options) zoneDescription() string {
+	switch o.Timezone {
+	case "", "author":
+		return "each author's time zone"
+	case "people":
+		return "each developer's time zone"
+	default:
+		return o.Timezone
+	}
+}
+
+// windowBounds returns the bounds of the window as exported: dates, RFC 3339
+// timestamps, or "" if open or bounded by a tag.
+func (o Options) windowBounds() (start, end string) {
+	start, end = o.StartDate, o.EndDate
+	if !o.Start.IsZero() {
+		start = o.Start.Format(time.RFC3339)
+	}
+	if !o.End.IsZero() {
+		end = o.End.Format(time.RFC3339)
+	}
+	return start, end
+}
+
+// windowTimes returns the bounds of the window as times, with days from
+// midnight to the end of the day in UTC, or zero times if open or bounded by
+// a tag.
+func (o Options) windowTimes() (start, end time.Time) {
+	start, end = o.Start, o.End
+	if day, err := time.Parse(internal.DayLayout, o.StartDate); err == nil {
+		start = day
+	}
+	if day, err := time.Parse(internal.DayLayout, o.EndDate); err == nil {
+		end = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return start, end
+}
+
+// countSet returns the number of true values.
+func countSet(values ...bool) int {
+	n := 0
+	for _, value := range values {
+		if value {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,36 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowTimes(t *testing.T) {
+	timestamp := time.Date(2024, time.March, 15, 9, 30, 0, 0, time.FixedZone("", 3600))
+
+	tests := []struct {
+		name  string
+		opts  Options
+		start time.Time
+		end   time.Time
+	}{
+		{"open", Options{}, time.Time{}, time.Time{}},
+		{
+			"dates include the whole end day",
+			Options{StartDate: "2024-02-01", EndDate: "2024-02-29"},
+			time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 29, 23, 59, 59, 999999999, time.UTC),
+		},
+		{"timestamps are exact", Options{Start: timestamp, End: timestamp}, timestamp, timestamp},
+		{"tags", Options{SinceTag: "v1", UntilTag: "v2"}, time.Time{}, time.Time{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end := test.opts.windowTimes()
+			if !start.Equal(test.start) || !end.Equal(test.end) {
+				t.Errorf("windowTimes() = %v, %v, want %v, %v", start, end, test.start, test.end)
+			}
+		})
+	}
+}